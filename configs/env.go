@@ -3,7 +3,9 @@ package configs
 import (
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -31,3 +33,154 @@ func AllowedOrigins() []string {
 	}
 	return strings.Split(os.Getenv("CORS_ALLOWED_LIST"), ",")
 }
+
+func JobWorkerCount() int {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	workers, err := strconv.Atoi(os.Getenv("JOB_WORKERS"))
+	if err != nil || workers < 1 {
+		return 2
+	}
+	return workers
+}
+
+// AdminUserIds are the Keycloak subjects allowed to use admin endpoints.
+func AdminUserIds() []string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return strings.Split(os.Getenv("ADMIN_USER_IDS"), ",")
+}
+
+// CookingSessionTTL is how long a cooking session survives without activity.
+func CookingSessionTTL() time.Duration {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	ttl, err := time.ParseDuration(os.Getenv("COOKING_SESSION_TTL"))
+	if err != nil || ttl <= 0 {
+		return 12 * time.Hour
+	}
+	return ttl
+}
+
+// PublishRequiresImage is whether recipes need an image before they can be
+// published.
+func PublishRequiresImage() bool {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	required, _ := strconv.ParseBool(os.Getenv("PUBLISH_REQUIRE_IMAGE"))
+	return required
+}
+
+// SMTPSettings is the mail server notification emails are sent through.
+// Username may be left empty for servers that don't need authentication,
+// like a local SMTP sink.
+type SMTPSettings struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTP returns nil when SMTP_HOST isn't set, which turns email
+// notifications off.
+func SMTP() *SMTPSettings {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if os.Getenv("SMTP_HOST") == "" {
+		return nil
+	}
+	settings := &SMTPSettings{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if settings.Port == "" {
+		settings.Port = "25"
+	}
+	if settings.From == "" {
+		settings.From = "notifications@localhost"
+	}
+	return settings
+}
+
+// EventBus picks where domain events are published: "local" keeps them in
+// the process, "nats" sends them to the NATS server at NATS_URL.
+func EventBus() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if bus := strings.ToLower(os.Getenv("EVENT_BUS")); bus != "" {
+		return bus
+	}
+	return "local"
+}
+
+// EventBusReachesAllReplicas is whether an event published by one copy of
+// the API is handed to the subscribers of every copy. Only the change stream
+// leader publishes changes, so the handlers on each copy may only leave
+// recipe webhooks, live updates and gRPC change streams to the bus when it
+// reaches all of them. The local bus only reaches the copy that published,
+// so with it the handlers keep doing that work themselves.
+func EventBusReachesAllReplicas() bool {
+	return EventBus() == "nats"
+}
+
+func NatsURL() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if url := os.Getenv("NATS_URL"); url != "" {
+		return url
+	}
+	return "nats://127.0.0.1:4222"
+}
+
+// NatsSubjectPrefix is put in front of event types to make NATS subjects.
+func NatsSubjectPrefix() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if prefix := os.Getenv("NATS_SUBJECT_PREFIX"); prefix != "" {
+		return prefix
+	}
+	return "tablerecipes"
+}
+
+// ChangeStreamsEnabled is whether changes to recipes and users are read
+// from MongoDB change streams, which needs a replica set.
+func ChangeStreamsEnabled() bool {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv("CHANGE_STREAMS_ENABLED"))
+	return enabled
+}
+
+// GrpcPort is the port the gRPC API listens on, next to HTTP on 8080.
+func GrpcPort() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if port := os.Getenv("GRPC_PORT"); port != "" {
+		return port
+	}
+	return "9090"
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+var errMissingToken = errors.New("missing Authorization header")
+
+// keycloakUserKey caches the validated user on the request, so handlers and
+// the filters they use can all ask for it without asking Keycloak again.
+const keycloakUserKey = "keycloakUser"
+
+// validateUser passes the request's bearer token on to Keycloak's userinfo
+// endpoint and returns the user it belongs to.
+func validateUser(c *gin.Context) (models.KeycloakUser, error) {
+	if cached, ok := c.Get(keycloakUserKey); ok {
+		return cached.(models.KeycloakUser), nil
+	}
+	keycloakUser, err := keycloakUserInfo(c.GetHeader("Authorization"))
+	if err != nil {
+		return keycloakUser, err
+	}
+	c.Set(keycloakUserKey, keycloakUser)
+	return keycloakUser, nil
+}
+
+// keycloakUserInfo is validateUser for an Authorization header value that
+// didn't come with a gin request.
+func keycloakUserInfo(authHeaderValue string) (models.KeycloakUser, error) {
+	var keycloakUser models.KeycloakUser
+	if authHeaderValue == "" {
+		return keycloakUser, errMissingToken
+	}
+
+	log.Println("Validating user token with Keycloak...")
+	keycloakHttpClient := &http.Client{}
+	req, err := http.NewRequest("GET", configs.EnvUserInfoURI(), nil)
+	if err != nil {
+		return keycloakUser, err
+	}
+	req.Header.Add("Authorization", authHeaderValue)
+	resp, err := keycloakHttpClient.Do(req)
+	if err != nil {
+		return keycloakUser, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return keycloakUser, fmt.Errorf("keycloak rejected token with status %d", resp.StatusCode)
+	}
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return keycloakUser, err
+	}
+	if err = json.Unmarshal(bodyBytes, &keycloakUser); err != nil {
+		return keycloakUser, err
+	}
+	if keycloakUser.Sub == "" {
+		return keycloakUser, errors.New("keycloak returned no subject for token")
+	}
+	return keycloakUser, nil
+}
+
+// requireUser is validateUser for handlers that can't continue without a
+// user. It writes the 401 response itself, so callers just return when ok is
+// false.
+func requireUser(c *gin.Context) (models.KeycloakUser, bool) {
+	keycloakUser, err := validateUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, responses.RecipeResponse{Status: http.StatusUnauthorized, Message: "error validating user", Data: map[string]interface{}{"data": err.Error()}})
+		return keycloakUser, false
+	}
+	return keycloakUser, true
+}
+
+// optionalUser is validateUser for endpoints that work anonymously but can
+// do more for a signed in user. A missing or rejected token just means no
+// user.
+func optionalUser(c *gin.Context) *models.KeycloakUser {
+	if c.GetHeader("Authorization") == "" {
+		return nil
+	}
+	keycloakUser, err := validateUser(c)
+	if err != nil {
+		log.Println("Ignoring invalid token on anonymous endpoint: ", err)
+		return nil
+	}
+	return &keycloakUser
+}
+
+// requireSelf is requireUser for /users/:id routes, which only the user
+// themselves may use. It writes the error response itself.
+func requireSelf(c *gin.Context) (models.KeycloakUser, bool) {
+	keycloakUser, ok := requireUser(c)
+	if !ok {
+		return keycloakUser, false
+	}
+	if keycloakUser.Sub != c.Param("id") {
+		c.JSON(http.StatusForbidden, responses.RecipeResponse{Status: http.StatusForbidden, Message: "error", Data: map[string]interface{}{"data": "token does not belong to user with ID " + c.Param("id")}})
+		return keycloakUser, false
+	}
+	return keycloakUser, true
+}
+
+// requireAdmin is requireUser for admin only endpoints. It writes the error
+// response itself.
+func requireAdmin(c *gin.Context) (models.KeycloakUser, bool) {
+	keycloakUser, ok := requireUser(c)
+	if !ok {
+		return keycloakUser, false
+	}
+	if !slices.Contains(configs.AdminUserIds(), keycloakUser.Sub) {
+		c.JSON(http.StatusForbidden, responses.RecipeResponse{Status: http.StatusForbidden, Message: "error", Data: map[string]interface{}{"data": "user with ID " + keycloakUser.Sub + " is not an admin"}})
+		return keycloakUser, false
+	}
+	return keycloakUser, true
+}
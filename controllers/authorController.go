@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultAuthorRecipeLimit = 20
+const maxAuthorRecipeLimit = 100
+const maxBioLength = 500
+
+// GetAuthor returns an author's public profile and a page of their public
+// recipes, newest first. ?page= starts at 1.
+func GetAuthor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var mongoUser models.MongoUser
+		defer cancel()
+
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "page must be a positive number"}})
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuthorRecipeLimit)))
+		if err != nil || limit < 1 || limit > maxAuthorRecipeLimit {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "limit must be between 1 and " + strconv.Itoa(maxAuthorRecipeLimit)}})
+			return
+		}
+
+		authorId := c.Param("id")
+		err = usersCollection.FindOne(ctx, bson.M{"_id": authorId}).Decode(&mongoUser)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		author, err := authorStats(ctx, authorId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if mongoUser.Profile != nil {
+			author.DisplayName, author.Bio, author.AvatarUrl = mongoUser.Profile.DisplayName, mongoUser.Profile.Bio, mongoUser.Profile.AvatarUrl
+		}
+		if mongoUser.Profile == nil && author.RecipeCount == 0 {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no author with ID " + authorId}})
+			return
+		}
+
+		findOptions := options.Find().
+			SetSort(bson.D{{Key: "publishedAt", Value: -1}, {Key: "_id", Value: -1}}).
+			SetSkip(int64((page - 1) * limit)).
+			SetLimit(int64(limit))
+		recipes, err := findRecipes(ctx, authorRecipesFilter(authorId), findOptions)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		totalPages := (author.RecipeCount + limit - 1) / limit
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched author with ID " + authorId, Data: map[string]interface{}{"data": author, "recipes": recipes, "page": page, "totalPages": totalPages}})
+	}
+}
+
+// UpdateAuthorProfile lets authors change their bio and avatar. It also
+// refreshes their display name from their Keycloak username, like
+// recordDisplayName.
+func UpdateAuthorProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireSelf(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var profileUpdate models.AuthorProfileUpdate
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&profileUpdate); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		now := time.Now().UTC()
+		profile := models.AuthorProfile{
+			DisplayName: keycloakUser.PreferredUsername,
+			Bio:         strings.TrimSpace(profileUpdate.Bio),
+			AvatarUrl:   strings.TrimSpace(profileUpdate.AvatarUrl),
+			UpdatedAt:   &now,
+		}
+		if len([]rune(profile.Bio)) > maxBioLength {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "bio can be at most " + strconv.Itoa(maxBioLength) + " characters"}})
+			return
+		}
+		if profile.AvatarUrl != "" {
+			if avatarUrl, err := url.Parse(profile.AvatarUrl); err != nil || (avatarUrl.Scheme != "http" && avatarUrl.Scheme != "https") || avatarUrl.Host == "" {
+				c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "avatarUrl must be an http or https URL"}})
+				return
+			}
+		}
+
+		_, err := usersCollection.UpdateOne(ctx, bson.M{"_id": keycloakUser.Sub}, bson.M{"$set": bson.M{"profile": profile}}, options.Update().SetUpsert(true))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully updated profile of author with ID " + keycloakUser.Sub, Data: map[string]interface{}{"data": profile}})
+	}
+}
+
+// recordDisplayName keeps the display name GetAuthor shows in step with the
+// user's Keycloak username. It's called when users create recipes, which is
+// what makes them authors. Errors are only logged, they aren't worth failing
+// the recipe over.
+func recordDisplayName(ctx context.Context, keycloakUser models.KeycloakUser) {
+	if keycloakUser.PreferredUsername == "" {
+		return
+	}
+	_, err := usersCollection.UpdateOne(ctx, bson.M{"_id": keycloakUser.Sub}, bson.M{"$set": bson.M{"profile.displayName": keycloakUser.PreferredUsername}}, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Println("Error recording display name of user with ID ", keycloakUser.Sub, ": ", err)
+	}
+}
+
+func authorRecipesFilter(authorId string) bson.M {
+	return andFilter([]bson.M{{"authorId": authorId}, discoverableFilter(nil)})
+}
+
+// authorStats counts an author's public recipes and followers, and averages
+// the ratings of those recipes weighted by how often each was rated.
+func authorStats(ctx context.Context, authorId string) (models.Author, error) {
+	authors, err := authorsStats(ctx, []string{authorId})
+	return authors[authorId], err
+}
+
+// authorsStats is authorStats for several authors at once. Every author
+// asked for is in the result, with zeros if they have nothing public.
+func authorsStats(ctx context.Context, authorIds []string) (map[string]models.Author, error) {
+	authors := map[string]models.Author{}
+	for _, authorId := range authorIds {
+		authors[authorId] = models.Author{Id: authorId}
+	}
+	pipeline := bson.A{
+		bson.M{"$match": andFilter([]bson.M{{"authorId": bson.M{"$in": authorIds}}, discoverableFilter(nil)})},
+		bson.M{"$group": bson.M{
+			"_id":         "$authorId",
+			"recipeCount": bson.M{"$sum": 1},
+			"ratingCount": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$ratingCount", 0}}},
+			"ratingTotal": bson.M{"$sum": bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{"$averageRating", 0}}, bson.M{"$ifNull": bson.A{"$ratingCount", 0}}}}},
+		}},
+	}
+	cursor, err := recipeCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return authors, err
+	}
+	var totals []struct {
+		AuthorId    string  `bson:"_id"`
+		RecipeCount int     `bson:"recipeCount"`
+		RatingCount int     `bson:"ratingCount"`
+		RatingTotal float64 `bson:"ratingTotal"`
+	}
+	if err = cursor.All(ctx, &totals); err != nil {
+		return authors, err
+	}
+	for _, total := range totals {
+		author := authors[total.AuthorId]
+		author.RecipeCount, author.RatingCount = total.RecipeCount, total.RatingCount
+		if author.RatingCount > 0 {
+			author.AverageRating = math.Round(total.RatingTotal/float64(author.RatingCount)*100) / 100
+		}
+		authors[total.AuthorId] = author
+	}
+
+	cursor, err = followCollection.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"authorId": bson.M{"$in": authorIds}}},
+		bson.M{"$group": bson.M{"_id": "$authorId", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return authors, err
+	}
+	var followers []struct {
+		AuthorId string `bson:"_id"`
+		Count    int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &followers); err != nil {
+		return authors, err
+	}
+	for _, count := range followers {
+		author := authors[count.AuthorId]
+		author.FollowerCount = count.Count
+		authors[count.AuthorId] = author
+	}
+	return authors, nil
+}
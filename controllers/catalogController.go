@@ -0,0 +1,374 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/ingredients"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var catalogCollection *mongo.Collection = configs.GetCollection(configs.DB, "ingredientCatalog")
+var unmatchedIngredientsCollection *mongo.Collection = configs.GetCollection(configs.DB, "unmatchedIngredients")
+
+// Only the first few lines are kept as examples of an unmatched ingredient.
+const maxUnmatchedExamples = 5
+
+// Every change to the catalog bumps this sequence. Each copy of the API
+// checks it this often and reloads its catalog when it moved, so a change
+// made through one copy reaches the others too.
+const catalogSequence = "ingredientCatalog"
+const catalogPollInterval = 30 * time.Second
+
+// loadedCatalog is the catalog sequence the normalizer's catalog was loaded
+// at. Holding the lock keeps loads from overtaking each other.
+var loadedCatalog struct {
+	sync.Mutex
+	version int64
+}
+
+// LoadIngredientCatalog reads the catalog from Mongo into the normalizer.
+// It runs at startup, after every change made through this copy of the API
+// and from PollIngredientCatalog for the changes made through others.
+func LoadIngredientCatalog(ctx context.Context) error {
+	loadedCatalog.Lock()
+	defer loadedCatalog.Unlock()
+	// Read the version first, a change made during the load is picked up
+	// by the next poll
+	version, err := currentSequence(ctx, catalogSequence)
+	if err != nil {
+		return err
+	}
+	entries, err := findCatalogIngredients(ctx)
+	if err != nil {
+		return err
+	}
+	ingredients.SetCatalog(ingredients.NewCatalog(entries))
+	loadedCatalog.version = version
+	log.Println("Loaded ", len(entries), " ingredients into the catalog")
+	return nil
+}
+
+// PollIngredientCatalog reloads the catalog whenever another copy of the API
+// changed it, until ctx is cancelled.
+func PollIngredientCatalog(ctx context.Context) {
+	ticker := time.NewTicker(catalogPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		version, err := currentSequence(dbCtx, catalogSequence)
+		loadedCatalog.Lock()
+		changed := err == nil && version != loadedCatalog.version
+		loadedCatalog.Unlock()
+		if err != nil {
+			log.Println("Error checking ingredient catalog version: ", err)
+		} else if changed {
+			if err = LoadIngredientCatalog(dbCtx); err != nil {
+				log.Println("Error reloading ingredient catalog: ", err)
+			}
+		}
+		cancel()
+	}
+}
+
+func findCatalogIngredients(ctx context.Context) ([]models.CatalogIngredient, error) {
+	entries := []models.CatalogIngredient{}
+	results, err := catalogCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+	for results.Next(ctx) {
+		var entry models.CatalogIngredient
+		if err = results.Decode(&entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, results.Err()
+}
+
+func GetCatalogIngredients() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		entries, err := findCatalogIngredients(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched ingredient catalog!", Data: map[string]interface{}{"data": entries}})
+	}
+}
+
+func GetCatalogIngredientById() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var entry models.CatalogIngredient
+		defer cancel()
+
+		err := catalogCollection.FindOne(ctx, bson.M{"_id": c.Param("id")}).Decode(&entry)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no catalog ingredient with ID " + c.Param("id")}})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched catalog ingredient with ID " + c.Param("id"), Data: map[string]interface{}{"data": entry}})
+	}
+}
+
+func PostCatalogIngredient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var entry models.CatalogIngredient
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&entry); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		entry.Id = primitive.NewObjectID().Hex()
+		if err := validateCatalogIngredient(&entry); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		entry.CreatedAt = time.Now().UTC()
+		entry.UpdatedAt = entry.CreatedAt
+
+		if _, err := catalogCollection.InsertOne(ctx, entry); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		afterCatalogChange(ctx)
+		c.JSON(http.StatusCreated, responses.RecipeResponse{Status: http.StatusCreated, Message: "Successfully created catalog ingredient!", Data: map[string]interface{}{"data": entry}})
+	}
+}
+
+func UpdateCatalogIngredientById() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var existing models.CatalogIngredient
+		var entry models.CatalogIngredient
+		defer cancel()
+
+		err := catalogCollection.FindOne(ctx, bson.M{"_id": c.Param("id")}).Decode(&existing)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no catalog ingredient with ID " + c.Param("id")}})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		//validate request body
+		if err := c.BindJSON(&entry); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		entry.Id = existing.Id
+		if err := validateCatalogIngredient(&entry); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		entry.CreatedAt = existing.CreatedAt
+		entry.UpdatedAt = time.Now().UTC()
+
+		if _, err = catalogCollection.ReplaceOne(ctx, bson.M{"_id": entry.Id}, entry); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		afterCatalogChange(ctx)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully updated catalog ingredient with ID " + entry.Id, Data: map[string]interface{}{"data": entry}})
+	}
+}
+
+func DeleteCatalogIngredientById() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := catalogCollection.DeleteOne(ctx, bson.M{"_id": c.Param("id")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		afterCatalogChange(ctx)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully deleted catalog ingredient with ID " + c.Param("id"), Data: map[string]interface{}{"data": result}})
+	}
+}
+
+func GetUnmatchedIngredients() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		unmatched := []models.UnmatchedIngredient{}
+		defer cancel()
+
+		// Most common first, those are worth curating before the rest
+		results, err := unmatchedIngredientsCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		defer results.Close(ctx)
+		if err = results.All(ctx, &unmatched); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched unmatched ingredients!", Data: map[string]interface{}{"data": unmatched}})
+	}
+}
+
+// DeleteUnmatchedIngredient dismisses an unmatched ingredient without adding
+// it to the catalog.
+func DeleteUnmatchedIngredient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := unmatchedIngredientsCollection.DeleteOne(ctx, bson.M{"_id": c.Param("id")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully dismissed unmatched ingredient " + c.Param("id"), Data: map[string]interface{}{"data": result}})
+	}
+}
+
+// validateCatalogIngredient cleans up the entry and makes sure none of its
+// names already belong to a different entry.
+func validateCatalogIngredient(entry *models.CatalogIngredient) error {
+	entry.Name = strings.TrimSpace(entry.Name)
+	if entry.Name == "" {
+		return errors.New("name is required")
+	}
+	if entry.DefaultDensity < 0 {
+		return errors.New("defaultDensity cannot be negative")
+	}
+	if entry.Synonyms == nil {
+		entry.Synonyms = []string{}
+	}
+	if entry.PluralForms == nil {
+		entry.PluralForms = []string{}
+	}
+	forms := append([]string{entry.Name}, entry.Synonyms...)
+	forms = append(forms, entry.PluralForms...)
+	catalog := ingredients.CurrentCatalog()
+	for _, form := range forms {
+		if existing := catalog.Owner(form); existing != nil && existing.Id != entry.Id {
+			return errors.New("'" + form + "' already belongs to catalog ingredient '" + existing.Name + "'")
+		}
+	}
+	return nil
+}
+
+// afterCatalogChange tells the other copies of the API about the change,
+// reloads the catalog and drops unmatched ingredients that now have a match.
+func afterCatalogChange(ctx context.Context) {
+	if _, err := nextSequence(ctx, catalogSequence); err != nil {
+		log.Println("Error bumping ingredient catalog version: ", err)
+	}
+	if err := LoadIngredientCatalog(ctx); err != nil {
+		log.Println("Error reloading ingredient catalog: ", err)
+		return
+	}
+	var unmatched []models.UnmatchedIngredient
+	results, err := unmatchedIngredientsCollection.Find(ctx, bson.M{})
+	if err != nil {
+		log.Println("Error reading unmatched ingredients: ", err)
+		return
+	}
+	defer results.Close(ctx)
+	if err = results.All(ctx, &unmatched); err != nil {
+		log.Println("Error reading unmatched ingredients: ", err)
+		return
+	}
+	catalog := ingredients.CurrentCatalog()
+	var resolved []string
+	for _, ingredient := range unmatched {
+		if catalog.LookupNormalized(ingredient.Id) != nil {
+			resolved = append(resolved, ingredient.Id)
+		}
+	}
+	if len(resolved) > 0 {
+		if _, err = unmatchedIngredientsCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": resolved}}); err != nil {
+			log.Println("Error removing resolved unmatched ingredients: ", err)
+		}
+	}
+}
+
+// queueUnmatchedIngredients records the recipe's ingredient lines that the
+// catalog can't map, grouped by normalized name, for an admin to curate.
+// previous are the lines before an update, which were already counted.
+// Failing to queue them shouldn't fail the request, so errors are only
+// logged.
+func queueUnmatchedIngredients(ctx context.Context, recipeId string, lines []string, previous []string) {
+	counted := map[string]bool{}
+	for _, line := range previous {
+		counted[ingredients.Normalize(line)] = true
+	}
+	catalog := ingredients.CurrentCatalog()
+	now := time.Now().UTC()
+	var writes []mongo.WriteModel
+	for _, line := range lines {
+		normalized := ingredients.Normalize(line)
+		if normalized == "" || counted[normalized] || ingredients.IsStaple(normalized) || catalog.LookupNormalized(normalized) != nil {
+			continue
+		}
+		counted[normalized] = true
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": normalized}).
+			SetUpdate(bson.M{
+				"$inc":         bson.M{"count": 1},
+				"$set":         bson.M{"lastSeen": now},
+				"$setOnInsert": bson.M{"firstSeen": now},
+				"$addToSet":    bson.M{"recipeIds": recipeId},
+				"$push":        bson.M{"examples": bson.M{"$each": bson.A{line}, "$slice": maxUnmatchedExamples}},
+			}).
+			SetUpsert(true))
+	}
+	if len(writes) == 0 {
+		return
+	}
+	if _, err := unmatchedIngredientsCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		log.Println("Error queueing unmatched ingredients for recipe with ID ", recipeId, ": ", err)
+	}
+}
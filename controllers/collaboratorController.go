@@ -0,0 +1,382 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/exp/slices"
+)
+
+var invitationCollection *mongo.Collection = configs.GetCollection(configs.DB, "recipeInvitations")
+
+// InviteCollaborator invites someone, by email address or username, to view
+// or edit one of the caller's recipes.
+func InviteCollaborator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var invitationRequest models.InvitationRequest
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&invitationRequest); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		invitee := strings.ToLower(strings.TrimSpace(invitationRequest.Invitee))
+		role := strings.ToLower(strings.TrimSpace(invitationRequest.Role))
+		if !slices.Contains(models.CollaboratorRoles, role) {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "role must be one of " + strings.Join(models.CollaboratorRoles, ", ")}})
+			return
+		}
+		if invitee == "" || isInvitee(invitee, keycloakUser) {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "invitee must be someone else's email address or username"}})
+			return
+		}
+		recipe, ok := findSharedRecipe(ctx, c, &keycloakUser)
+		if !ok {
+			return
+		}
+
+		pending, err := invitationCollection.CountDocuments(ctx, bson.M{"recipeId": recipe.Id, "invitee": invitee, "status": models.InvitationPending})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if pending > 0 {
+			c.JSON(http.StatusConflict, responses.RecipeResponse{Status: http.StatusConflict, Message: "error", Data: map[string]interface{}{"data": invitee + " already has a pending invitation to recipe with ID " + recipe.Id}})
+			return
+		}
+
+		invitation := models.Invitation{
+			Id:          primitive.NewObjectID().Hex(),
+			RecipeId:    recipe.Id,
+			RecipeTitle: recipe.Title,
+			InvitedBy:   keycloakUser.Sub,
+			Invitee:     invitee,
+			Role:        role,
+			Status:      models.InvitationPending,
+			CreatedAt:   time.Now().UTC(),
+		}
+		if _, err = invitationCollection.InsertOne(ctx, invitation); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		log.Println("Invited ", invitee, " to recipe with ID ", recipe.Id, " as ", role)
+		c.JSON(http.StatusCreated, responses.RecipeResponse{Status: http.StatusCreated, Message: "Successfully invited " + invitee + " to recipe with ID " + recipe.Id, Data: map[string]interface{}{"data": invitation}})
+	}
+}
+
+// GetRecipeCollaborators lists who a recipe is shared with. Only the author
+// also sees the invitations still waiting for an answer.
+func GetRecipeCollaborators() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), &keycloakUser)
+		if !ok {
+			return
+		}
+		if recipe.AuthorId != keycloakUser.Sub && collaboratorRole(recipe, keycloakUser.Sub) == "" {
+			c.JSON(http.StatusForbidden, responses.RecipeResponse{Status: http.StatusForbidden, Message: "error", Data: map[string]interface{}{"data": "recipe with ID " + recipe.Id + " isn't shared with user with ID " + keycloakUser.Sub}})
+			return
+		}
+
+		collaborators := recipe.Collaborators
+		if collaborators == nil {
+			collaborators = []models.Collaborator{}
+		}
+		data := map[string]interface{}{"data": collaborators}
+		if recipe.AuthorId == keycloakUser.Sub {
+			invitations, err := findInvitations(ctx, bson.M{"recipeId": recipe.Id, "status": models.InvitationPending})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+				return
+			}
+			data["invitations"] = invitations
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched collaborators of recipe with ID " + recipe.Id, Data: data})
+	}
+}
+
+// UpdateCollaboratorRole switches a collaborator between editor and viewer.
+func UpdateCollaboratorRole() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var requestBody struct {
+			Role string `json:"role" binding:"required"`
+		}
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&requestBody); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		role := strings.ToLower(strings.TrimSpace(requestBody.Role))
+		if !slices.Contains(models.CollaboratorRoles, role) {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "role must be one of " + strings.Join(models.CollaboratorRoles, ", ")}})
+			return
+		}
+		recipe, ok := findSharedRecipe(ctx, c, &keycloakUser)
+		if !ok {
+			return
+		}
+
+		result, err := recipeCollection.UpdateOne(ctx, bson.M{"_id": recipe.Id, "collaborators.userId": c.Param("userId")}, bson.M{"$set": bson.M{"collaborators.$.role": role}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "user with ID " + c.Param("userId") + " isn't a collaborator on recipe with ID " + recipe.Id}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully changed role of user with ID " + c.Param("userId") + " to " + role, Data: map[string]interface{}{"data": result}})
+	}
+}
+
+// RemoveCollaborator revokes a collaborator's access. The author can remove
+// anyone, collaborators can remove themselves.
+func RemoveCollaborator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), &keycloakUser)
+		if !ok {
+			return
+		}
+		if recipe.AuthorId != keycloakUser.Sub && c.Param("userId") != keycloakUser.Sub {
+			c.JSON(http.StatusForbidden, responses.RecipeResponse{Status: http.StatusForbidden, Message: "error", Data: map[string]interface{}{"data": "only the author can remove other collaborators from recipe with ID " + recipe.Id}})
+			return
+		}
+
+		result, err := recipeCollection.UpdateByID(ctx, recipe.Id, bson.M{"$pull": bson.M{"collaborators": bson.M{"userId": c.Param("userId")}}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		log.Println("Removed user with ID ", c.Param("userId"), " from recipe with ID ", recipe.Id)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully removed user with ID " + c.Param("userId") + " from recipe with ID " + recipe.Id, Data: map[string]interface{}{"data": result}})
+	}
+}
+
+// RevokeInvitation withdraws an invitation that hasn't been answered yet.
+func RevokeInvitation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		recipe, ok := findSharedRecipe(ctx, c, &keycloakUser)
+		if !ok {
+			return
+		}
+		invitation, ok := respondToInvitation(ctx, c, c.Param("invitationId"), bson.M{"recipeId": recipe.Id}, bson.M{"status": models.InvitationRevoked})
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully revoked invitation with ID " + invitation.Id, Data: map[string]interface{}{"data": invitation}})
+	}
+}
+
+// GetMyInvitations lists the pending invitations addressed to the caller's
+// email address or username.
+func GetMyInvitations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		invitations, err := findInvitations(ctx, bson.M{"invitee": bson.M{"$in": inviteeNames(keycloakUser)}, "status": models.InvitationPending})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched invitations for user with ID " + keycloakUser.Sub, Data: map[string]interface{}{"data": invitations}})
+	}
+}
+
+// AcceptInvitation adds the caller to the recipe with the invited role. If
+// they already collaborate on it, their role is replaced.
+func AcceptInvitation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := bson.M{"invitee": bson.M{"$in": inviteeNames(keycloakUser)}}
+		invitation, ok := respondToInvitation(ctx, c, c.Param("id"), filter, bson.M{"status": models.InvitationAccepted, "acceptedBy": keycloakUser.Sub})
+		if !ok {
+			return
+		}
+
+		collaborator := models.Collaborator{UserId: keycloakUser.Sub, Role: invitation.Role, AddedAt: time.Now().UTC()}
+		result, err := recipeCollection.UpdateOne(ctx,
+			bson.M{"_id": invitation.RecipeId, "authorId": bson.M{"$ne": keycloakUser.Sub}, "collaborators.userId": bson.M{"$ne": keycloakUser.Sub}},
+			bson.M{"$push": bson.M{"collaborators": collaborator}})
+		if err == nil && result.MatchedCount == 0 {
+			result, err = recipeCollection.UpdateOne(ctx,
+				bson.M{"_id": invitation.RecipeId, "collaborators.userId": keycloakUser.Sub},
+				bson.M{"$set": bson.M{"collaborators.$.role": invitation.Role}})
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "recipe with ID " + invitation.RecipeId + " no longer exists or is your own"}})
+			return
+		}
+		log.Println("User with ID ", keycloakUser.Sub, " joined recipe with ID ", invitation.RecipeId, " as ", invitation.Role)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully accepted invitation to recipe with ID " + invitation.RecipeId, Data: map[string]interface{}{"data": invitation}})
+	}
+}
+
+func DeclineInvitation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := bson.M{"invitee": bson.M{"$in": inviteeNames(keycloakUser)}}
+		invitation, ok := respondToInvitation(ctx, c, c.Param("id"), filter, bson.M{"status": models.InvitationDeclined})
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully declined invitation to recipe with ID " + invitation.RecipeId, Data: map[string]interface{}{"data": invitation}})
+	}
+}
+
+// GetSharedRecipes lists the recipes other authors have shared with the
+// caller.
+func GetSharedRecipes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		recipes, err := findRecipes(ctx, bson.M{"collaborators.userId": keycloakUser.Sub}, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched recipes shared with user with ID " + keycloakUser.Sub, Data: map[string]interface{}{"data": recipes}})
+	}
+}
+
+// findSharedRecipe loads the recipe in the path for managing who it's shared
+// with, which only its author may do.
+func findSharedRecipe(ctx context.Context, c *gin.Context, keycloakUser *models.KeycloakUser) (models.Recipe, bool) {
+	recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), keycloakUser)
+	if !ok {
+		return recipe, false
+	}
+	if recipe.AuthorId != keycloakUser.Sub {
+		c.JSON(http.StatusForbidden, responses.RecipeResponse{Status: http.StatusForbidden, Message: "error", Data: map[string]interface{}{"data": "only the author can manage who recipe with ID " + recipe.Id + " is shared with"}})
+		return recipe, false
+	}
+	return recipe, true
+}
+
+// respondToInvitation moves a pending invitation that also matches filter to
+// the state in set, writing a 404 when there's no such pending invitation.
+func respondToInvitation(ctx context.Context, c *gin.Context, invitationId string, filter bson.M, set bson.M) (models.Invitation, bool) {
+	var invitation models.Invitation
+	filter["_id"], filter["status"] = invitationId, models.InvitationPending
+	set["respondedAt"] = time.Now().UTC()
+	err := invitationCollection.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&invitation)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no pending invitation with ID " + invitationId}})
+		return invitation, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+		return invitation, false
+	}
+	return invitation, true
+}
+
+func findInvitations(ctx context.Context, filter bson.M) ([]models.Invitation, error) {
+	invitations := []models.Invitation{}
+	results, err := invitationCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = results.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// revokeRecipeInvitations withdraws every pending invitation to a recipe
+// that's being deleted. Failing only leaves invitations that can't be
+// accepted, so it's logged rather than returned.
+func revokeRecipeInvitations(ctx context.Context, recipeId string) {
+	_, err := invitationCollection.UpdateMany(ctx, bson.M{"recipeId": recipeId, "status": models.InvitationPending},
+		bson.M{"$set": bson.M{"status": models.InvitationRevoked, "respondedAt": time.Now().UTC()}})
+	if err != nil {
+		log.Println("Error revoking invitations to recipe with ID ", recipeId, ": ", err)
+	}
+}
+
+// inviteeNames are the names an invitation to this user could have been
+// addressed to. Email addresses only count once Keycloak has verified them.
+func inviteeNames(keycloakUser models.KeycloakUser) []string {
+	names := []string{}
+	if keycloakUser.PreferredUsername != "" {
+		names = append(names, strings.ToLower(keycloakUser.PreferredUsername))
+	}
+	if keycloakUser.Email != "" && keycloakUser.EmailVerified {
+		names = append(names, strings.ToLower(keycloakUser.Email))
+	}
+	return names
+}
+
+func isInvitee(invitee string, keycloakUser models.KeycloakUser) bool {
+	return slices.Contains(inviteeNames(keycloakUser), invitee)
+}
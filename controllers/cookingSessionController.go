@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/ingredients"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var cookingSessionCollection *mongo.Collection = configs.GetCollection(configs.DB, "cookingSessions")
+
+// EnsureCookingSessionIndexes creates the TTL index that drops sessions once
+// they expire, and the index used to list a user's sessions.
+func EnsureCookingSessionIndexes(ctx context.Context) error {
+	_, err := cookingSessionCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "updatedAt", Value: -1}}},
+	})
+	return err
+}
+
+// StartCookingSession starts cooking the recipe, or resumes the session the
+// user already has for it. An optional servings in the body scales the
+// ingredients.
+func StartCookingSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var update models.CookingSessionUpdate
+		defer cancel()
+
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&update); err != nil {
+				c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+				return
+			}
+		}
+		recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), &keycloakUser)
+		if !ok {
+			return
+		}
+		servings := recipe.Servings
+		if update.Servings != nil {
+			if *update.Servings < 1 {
+				c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "servings must be at least 1"}})
+				return
+			}
+			servings = *update.Servings
+		}
+
+		sessionId := cookingSessionId(keycloakUser.Sub, recipe.Id)
+		now := time.Now().UTC()
+		// The TTL monitor only runs every minute or so, clear out an expired
+		// session ourselves so it isn't resumed.
+		if _, err := cookingSessionCollection.DeleteOne(ctx, bson.M{"_id": sessionId, "expiresAt": bson.M{"$lte": now}}); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		set := bson.M{"updatedAt": now, "expiresAt": now.Add(configs.CookingSessionTTL())}
+		setOnInsert := bson.M{"userId": keycloakUser.Sub, "recipeId": recipe.Id, "currentStep": 0, "checkedIngredients": []int{}, "startedAt": now}
+		if update.Servings != nil {
+			set["servings"] = servings
+		} else {
+			setOnInsert["servings"] = servings
+		}
+		var session models.CookingSession
+		err := cookingSessionCollection.FindOneAndUpdate(ctx, bson.M{"_id": sessionId}, bson.M{"$set": set, "$setOnInsert": setOnInsert},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&session)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		log.Println("Cooking session for recipe with ID ", recipe.Id, " at step ", session.CurrentStep, " for user with ID ", keycloakUser.Sub)
+		scaleSessionIngredients(&session, recipe)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully started cooking session for recipe with ID " + recipe.Id, Data: map[string]interface{}{"data": session}})
+	}
+}
+
+func GetCookingSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		session, ok := findCookingSession(ctx, c, keycloakUser.Sub)
+		if !ok {
+			return
+		}
+		recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), &keycloakUser)
+		if !ok {
+			return
+		}
+		scaleSessionIngredients(session, recipe)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched cooking session for recipe with ID " + c.Param("id"), Data: map[string]interface{}{"data": session}})
+	}
+}
+
+// UpdateCookingSession moves the session to another step, rescales it or
+// changes which ingredients are checked off. Every update keeps the session
+// alive for another CookingSessionTTL.
+func UpdateCookingSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var update models.CookingSessionUpdate
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		session, ok := findCookingSession(ctx, c, keycloakUser.Sub)
+		if !ok {
+			return
+		}
+		recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), &keycloakUser)
+		if !ok {
+			return
+		}
+
+		now := time.Now().UTC()
+		set := bson.M{"updatedAt": now, "expiresAt": now.Add(configs.CookingSessionTTL())}
+		if update.CurrentStep != nil {
+			if *update.CurrentStep < 0 || *update.CurrentStep >= len(recipe.Instructions) {
+				c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "currentStep must be between 0 and " + strconv.Itoa(len(recipe.Instructions)-1)}})
+				return
+			}
+			set["currentStep"] = *update.CurrentStep
+		}
+		if update.Servings != nil {
+			if *update.Servings < 1 {
+				c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "servings must be at least 1"}})
+				return
+			}
+			set["servings"] = *update.Servings
+		}
+		if update.CheckedIngredients != nil {
+			checked := []int{}
+			seen := map[int]bool{}
+			for _, ingredient := range update.CheckedIngredients {
+				if ingredient < 0 || ingredient >= len(recipe.Ingredients) {
+					c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "ingredient " + strconv.Itoa(ingredient) + " doesn't exist"}})
+					return
+				}
+				if !seen[ingredient] {
+					seen[ingredient] = true
+					checked = append(checked, ingredient)
+				}
+			}
+			set["checkedIngredients"] = checked
+		}
+
+		err := cookingSessionCollection.FindOneAndUpdate(ctx, bson.M{"_id": session.Id}, bson.M{"$set": set},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(session)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no cooking session for recipe with ID " + recipe.Id}})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		scaleSessionIngredients(session, recipe)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully updated cooking session for recipe with ID " + recipe.Id, Data: map[string]interface{}{"data": session}})
+	}
+}
+
+// FinishCookingSession ends the session for the recipe.
+func FinishCookingSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := cookingSessionCollection.DeleteOne(ctx, bson.M{"_id": cookingSessionId(keycloakUser.Sub, c.Param("id"))})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully finished cooking session for recipe with ID " + c.Param("id"), Data: map[string]interface{}{"data": result}})
+	}
+}
+
+// GetUserCookingSessions lists the user's active sessions, most recently
+// used first, so another device can offer to resume one.
+func GetUserCookingSessions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		filter := bson.M{"userId": c.Param("id"), "expiresAt": bson.M{"$gt": time.Now().UTC()}}
+		results, err := cookingSessionCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"updatedAt": -1}))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		sessions := []models.CookingSession{}
+		if err = results.All(ctx, &sessions); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched cooking sessions for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": sessions}})
+	}
+}
+
+func cookingSessionId(userId string, recipeId string) string {
+	return userId + ":" + recipeId
+}
+
+// findCookingSession loads the caller's unexpired session for the recipe in
+// the path, writing a 404 when there isn't one.
+func findCookingSession(ctx context.Context, c *gin.Context, userId string) (*models.CookingSession, bool) {
+	var session models.CookingSession
+	filter := bson.M{"_id": cookingSessionId(userId, c.Param("id")), "expiresAt": bson.M{"$gt": time.Now().UTC()}}
+	err := cookingSessionCollection.FindOne(ctx, filter).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no cooking session for recipe with ID " + c.Param("id")}})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+		return nil, false
+	}
+	return &session, true
+}
+
+// scaleSessionIngredients fills in the recipe's ingredients scaled from the
+// servings it was written for to the session's.
+func scaleSessionIngredients(session *models.CookingSession, recipe models.Recipe) {
+	factor := 1.0
+	if recipe.Servings > 0 && session.Servings > 0 {
+		factor = float64(session.Servings) / float64(recipe.Servings)
+	}
+	session.ScaledIngredients = make([]string, len(recipe.Ingredients))
+	for i, line := range recipe.Ingredients {
+		session.ScaledIngredients[i] = ingredients.Scale(line, factor)
+	}
+}
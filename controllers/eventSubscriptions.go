@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hopk8412/table-recipes-api/events"
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recipeWebhooksFromEvents is set once recipe webhooks are sent from the
+// event bus, which also sees changes made outside the handlers. The
+// handlers stop sending them then so subscribers don't get them twice.
+var recipeWebhooksFromEvents bool
+
+// eventUser is what user events carry. The user document also holds their
+// email, pantry, preferences and webhook secret, which must not leave the
+// API; which fields changed is already in the event's ChangedFields.
+type eventUser struct {
+	Id      string                `bson:"_id" json:"id"`
+	Profile *models.AuthorProfile `bson:"profile,omitempty" json:"profile,omitempty"`
+}
+
+// EventSources are the collections whose changes are published as events.
+func EventSources() []events.Source {
+	return []events.Source{
+		{Collection: recipeCollection, Entity: "recipe", Decode: func(document bson.Raw) (interface{}, error) {
+			var recipe models.Recipe
+			err := bson.Unmarshal(document, &recipe)
+			return recipe, err
+		}},
+		{Collection: usersCollection, Entity: "user", Decode: func(document bson.Raw) (interface{}, error) {
+			var user eventUser
+			err := bson.Unmarshal(document, &user)
+			return user, err
+		}},
+	}
+}
+
+// SubscribeToEvents has the API react to events on the bus. It is meant to
+// be called during startup when change streams are turned on and the bus
+// reaches every copy of the API, see configs.EventBusReachesAllReplicas.
+func SubscribeToEvents(bus events.Bus) error {
+	err := bus.Subscribe("webhooks", "recipe.*", func(ctx context.Context, event events.Event) error {
+		publishWebhookEvent(ctx, event.Type, event.Data)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	recipeWebhooksFromEvents = true
+
+	err = bus.Subscribe("live", events.RecipeUpdated, func(ctx context.Context, event events.Event) error {
+		// The recipe is a models.Recipe on the local bus and a map once it
+		// went through NATS, JSON reads both
+		raw, err := json.Marshal(event.Data["recipe"])
+		if err != nil {
+			return err
+		}
+		var recipe models.Recipe
+		if err = json.Unmarshal(raw, &recipe); err != nil {
+			return err
+		}
+		if recipe.Id == "" {
+			// Deleted before the change stream looked it up
+			return nil
+		}
+		recordLiveRecipeEvent(ctx, models.RecipeEvent{RecipeId: recipe.Id, Type: models.RecipeEventUpdate, Recipe: &recipe})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	liveUpdatesFromEvents = true
+
+	// Every copy of the API streams changes to its own gRPC clients, so each
+	// needs a subscription of its own
+	err = bus.Subscribe("grpc-changes-"+primitive.NewObjectID().Hex(), "recipe.*", func(ctx context.Context, event events.Event) error {
+		recipeChanges.publish(event)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	recipeChangesFromEvents = true
+	return nil
+}
+
+// publishRecipeWebhook is publishWebhookEvent for recipe changes made by the
+// handlers, unless the event bus is already taking care of them.
+func publishRecipeWebhook(ctx context.Context, eventType string, data map[string]interface{}) {
+	if recipeWebhooksFromEvents {
+		return
+	}
+	publishWebhookEvent(ctx, eventType, data)
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultFeedLimit = 20
+const maxFeedLimit = 100
+
+// Edits within this long of each other show up as a single update.
+const feedUpdateWindow = time.Hour
+
+var feedCollection *mongo.Collection = configs.GetCollection(configs.DB, "feedEvents")
+var followCollection *mongo.Collection = configs.GetCollection(configs.DB, "follows")
+
+// EnsureFeedIndexes creates the indexes used to build and stream feeds and
+// list followers.
+func EnsureFeedIndexes(ctx context.Context) error {
+	_, err := feedCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "actorId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "recipientId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "recipeId", Value: 1}, {Key: "type", Value: 1}}},
+		{Keys: bson.D{{Key: "actorId", Value: 1}, {Key: "sequence", Value: 1}}},
+		{Keys: bson.D{{Key: "recipientId", Value: 1}, {Key: "sequence", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = followCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "followerId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "authorId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = reviewCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "recipeId", Value: 1}, {Key: "updatedAt", Value: -1}}})
+	return err
+}
+
+// GetUserFeed pages through new and updated public recipes from the authors
+// the user follows and reviews of the user's own recipes, newest first. Pass
+// the nextBefore of one page as ?before= to get the next.
+func GetUserFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultFeedLimit)))
+		if err != nil || limit < 1 || limit > maxFeedLimit {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "limit must be between 1 and " + strconv.Itoa(maxFeedLimit)}})
+			return
+		}
+		before := time.Now().UTC()
+		if c.Query("before") != "" {
+			if before, err = time.Parse(time.RFC3339Nano, c.Query("before")); err != nil {
+				c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "before must be an RFC 3339 timestamp"}})
+				return
+			}
+		}
+
+		following, err := followedAuthorIds(ctx, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		filter := feedFilter(c.Param("id"), following)
+		filter["createdAt"] = bson.M{"$lt": before}
+		// Fetch a few extra to make up for events on recipes that have
+		// since stopped being public.
+		findOptions := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(int64(limit * 2))
+		results, err := feedCollection.Find(ctx, filter, findOptions)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		var candidates []models.FeedEvent
+		if err = results.All(ctx, &candidates); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		visible, err := discoverableRecipeIds(ctx, candidates)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		events := []models.FeedEvent{}
+		var nextBefore string
+		for _, event := range candidates {
+			if len(events) == limit {
+				break
+			}
+			// Anything older than the last event looked at is for the next page
+			nextBefore = event.CreatedAt.Format(time.RFC3339Nano)
+			if event.Type == models.FeedReviewCreated || visible[event.RecipeId] {
+				events = append(events, event)
+			}
+		}
+		if len(candidates) < limit*2 && len(events) < limit {
+			nextBefore = ""
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched feed for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": events, "nextBefore": nextBefore}})
+	}
+}
+
+// feedFilter matches the events in a user's feed. Feeds fan out on read:
+// events are stored once per actor and gathered for each reader, so
+// following someone includes their history.
+func feedFilter(userId string, following []string) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"actorId": bson.M{"$in": following}, "type": bson.M{"$in": bson.A{models.FeedRecipePublished, models.FeedRecipeUpdated}}},
+		bson.M{"recipientId": userId, "type": models.FeedReviewCreated},
+	}}
+}
+
+// discoverableRecipeIds works out which of the events' recipes may still be
+// shown to anyone.
+func discoverableRecipeIds(ctx context.Context, events []models.FeedEvent) (map[string]bool, error) {
+	ids := []string{}
+	for _, event := range events {
+		ids = append(ids, event.RecipeId)
+	}
+	recipes, err := findRecipes(ctx, andFilter([]bson.M{{"_id": bson.M{"$in": ids}}, discoverableFilter(nil)}), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	visible := map[string]bool{}
+	for _, recipe := range recipes {
+		visible[recipe.Id] = true
+	}
+	return visible, nil
+}
+
+// recordRecipeEvent adds a published or updated recipe to its author's
+// followers' feeds, as long as the recipe is public. Updates close together
+// are folded into one event, which is renumbered so streams send it again.
+// Feeds are a side effect of the request, so failures are logged rather than
+// returned.
+func recordRecipeEvent(ctx context.Context, eventType string, recipe models.Recipe) {
+	if !isDiscoverable(recipe, nil) {
+		return
+	}
+	now := time.Now().UTC()
+	sequence, err := nextSequence(ctx, feedEventSequence)
+	if err != nil {
+		log.Println("Error numbering ", eventType, " for recipe with ID ", recipe.Id, ": ", err)
+		return
+	}
+	if eventType == models.FeedRecipeUpdated {
+		filter := bson.M{"type": eventType, "recipeId": recipe.Id, "createdAt": bson.M{"$gte": now.Add(-feedUpdateWindow)}}
+		update := bson.M{
+			"$set":         bson.M{"createdAt": now, "sequence": sequence, "recipeTitle": recipe.Title},
+			"$setOnInsert": bson.M{"_id": primitive.NewObjectID().Hex(), "actorId": recipe.AuthorId},
+		}
+		_, err = feedCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	} else {
+		_, err = feedCollection.InsertOne(ctx, models.FeedEvent{
+			Id:          primitive.NewObjectID().Hex(),
+			Sequence:    sequence,
+			Type:        eventType,
+			ActorId:     recipe.AuthorId,
+			RecipeId:    recipe.Id,
+			RecipeTitle: recipe.Title,
+			CreatedAt:   now,
+		})
+	}
+	if err != nil {
+		log.Println("Error recording ", eventType, " for recipe with ID ", recipe.Id, ": ", err)
+		return
+	}
+	liveStreams.notify(feedTopic)
+}
+
+// recordReviewEvent tells a recipe's author about a review of it.
+func recordReviewEvent(ctx context.Context, review models.Review, recipe models.Recipe) {
+	if review.UserId == recipe.AuthorId {
+		return
+	}
+	sequence, err := nextSequence(ctx, feedEventSequence)
+	if err != nil {
+		log.Println("Error numbering review of recipe with ID ", recipe.Id, ": ", err)
+		return
+	}
+	_, err = feedCollection.InsertOne(ctx, models.FeedEvent{
+		Id:          primitive.NewObjectID().Hex(),
+		Sequence:    sequence,
+		Type:        models.FeedReviewCreated,
+		ActorId:     review.UserId,
+		RecipientId: recipe.AuthorId,
+		RecipeId:    recipe.Id,
+		RecipeTitle: recipe.Title,
+		Rating:      review.Rating,
+		Comment:     review.Comment,
+		CreatedAt:   review.UpdatedAt,
+	})
+	if err != nil {
+		log.Println("Error recording review of recipe with ID ", recipe.Id, ": ", err)
+		return
+	}
+	liveStreams.notify(feedTopic)
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func FollowAuthor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		followerId, authorId := c.Param("id"), c.Param("authorId")
+		if followerId == authorId {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "users can't follow themselves"}})
+			return
+		}
+		follow := models.Follow{Id: followId(followerId, authorId), FollowerId: followerId, AuthorId: authorId, CreatedAt: time.Now().UTC()}
+		// Following someone twice is the same as following them once
+		setOnInsert := bson.M{"followerId": follow.FollowerId, "authorId": follow.AuthorId, "createdAt": follow.CreatedAt}
+		_, err := followCollection.UpdateOne(ctx, bson.M{"_id": follow.Id}, bson.M{"$setOnInsert": setOnInsert}, options.Update().SetUpsert(true))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		log.Println("User with ID ", followerId, " followed author with ID ", authorId)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully followed author with ID " + authorId, Data: map[string]interface{}{"data": follow}})
+	}
+}
+
+func UnfollowAuthor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := followCollection.DeleteOne(ctx, bson.M{"_id": followId(c.Param("id"), c.Param("authorId"))})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully unfollowed author with ID " + c.Param("authorId"), Data: map[string]interface{}{"data": result}})
+	}
+}
+
+// GetFollowing lists the authors a user follows. Follows are public.
+func GetFollowing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		follows, err := findFollows(ctx, bson.M{"followerId": c.Param("id")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched authors followed by user with ID " + c.Param("id"), Data: map[string]interface{}{"data": follows}})
+	}
+}
+
+// GetFollowers lists the users following an author.
+func GetFollowers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		follows, err := findFollows(ctx, bson.M{"authorId": c.Param("id")})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched followers of user with ID " + c.Param("id"), Data: map[string]interface{}{"data": follows}})
+	}
+}
+
+func followId(followerId string, authorId string) string {
+	return followerId + ":" + authorId
+}
+
+func findFollows(ctx context.Context, filter bson.M) ([]models.Follow, error) {
+	follows := []models.Follow{}
+	results, err := followCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = results.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
+
+func followedAuthorIds(ctx context.Context, followerId string) ([]string, error) {
+	follows, err := findFollows(ctx, bson.M{"followerId": followerId})
+	if err != nil {
+		return nil, err
+	}
+	authorIds := make([]string, len(follows))
+	for i, follow := range follows {
+		authorIds[i] = follow.AuthorId
+	}
+	return authorIds, nil
+}
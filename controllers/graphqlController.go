@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Queries nesting fields deeper than this, or costing more, are rejected
+// before anything is loaded. Every field costs 1, and list fields multiply
+// the cost of the fields under them by their limit, so the cost roughly
+// counts the values in the response.
+const graphqlMaxDepth = 7
+const graphqlMaxComplexity = 2000
+
+type graphqlParams struct {
+	Query         string                 `json:"query" form:"query"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// GraphQL serves the GraphQL API, over POST with a JSON body or over GET
+// with the query in ?query=. Responses use GraphQL's own format rather than
+// RecipeResponse, since that's what GraphQL clients expect.
+func GraphQL() gin.HandlerFunc {
+	schema, err := newGraphqlSchema()
+	if err != nil {
+		log.Fatal("Error building GraphQL schema: ", err)
+	}
+	return func(c *gin.Context) {
+		var params graphqlParams
+		var err error
+		if c.Request.Method == http.MethodGet {
+			err = c.BindQuery(&params)
+		} else {
+			err = c.BindJSON(&params)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+
+		document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(params.Query), Name: "GraphQL request"})})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		if validation := graphql.ValidateDocument(&schema, document, nil); !validation.IsValid {
+			c.JSON(http.StatusBadRequest, graphql.Result{Errors: validation.Errors})
+			return
+		}
+		operation, err := graphqlOperation(document, params.OperationName)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		if c.Request.Method == http.MethodGet && operation.Operation != ast.OperationTypeQuery {
+			c.JSON(http.StatusMethodNotAllowed, graphql.Result{Errors: gqlerrors.FormatErrors(errors.New("mutations must be sent with POST"))})
+			return
+		}
+		if err = checkGraphqlCost(schema, document, operation, params.Variables); err != nil {
+			c.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+
+		// A missing or rejected token just means no user, like optionalUser,
+		// but fields that need one say why there isn't one
+		request := &graphqlRequest{viewerErr: errMissingToken}
+		if c.GetHeader("Authorization") != "" {
+			keycloakUser, err := validateUser(c)
+			if err != nil {
+				request.viewerErr = err
+			} else {
+				request.viewer = &keycloakUser
+			}
+		}
+		request.loaders = newGraphqlLoaders(request.viewer)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        schema,
+			AST:           document,
+			OperationName: params.OperationName,
+			Args:          params.Variables,
+			Context:       context.WithValue(ctx, graphqlRequestKey{}, request),
+		})
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// graphqlOperation picks the operation a request runs: the one named, or
+// the only one there is.
+func graphqlOperation(document *ast.Document, operationName string) (*ast.OperationDefinition, error) {
+	var found *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" && found != nil {
+			return nil, errors.New("operationName is required when sending several operations")
+		}
+		if operationName == "" || (operation.Name != nil && operation.Name.Value == operationName) {
+			found = operation
+		}
+	}
+	if found == nil {
+		return nil, errors.New("no operation named " + strconv.Quote(operationName))
+	}
+	return found, nil
+}
+
+// checkGraphqlCost rejects operations nesting deeper than graphqlMaxDepth or
+// costing more than graphqlMaxComplexity. The document must be valid.
+func checkGraphqlCost(schema graphql.Schema, document *ast.Document, operation *ast.OperationDefinition, variables map[string]interface{}) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	walker := graphqlCostWalker{schema: schema, fragments: fragments, variables: variables}
+	depth, complexity := walker.walk(operation.SelectionSet, root)
+	if depth > graphqlMaxDepth {
+		return fmt.Errorf("query nests fields %d deep, at most %d are allowed", depth, graphqlMaxDepth)
+	}
+	if complexity > graphqlMaxComplexity {
+		return fmt.Errorf("query has a complexity of %d, at most %d is allowed, ask for fewer fields or smaller pages", complexity, graphqlMaxComplexity)
+	}
+	return nil
+}
+
+type graphqlCostWalker struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// walk returns how deep the fields of a selection set nest and what they
+// cost. Introspection is free, its depth is bounded by the schema.
+func (walker graphqlCostWalker) walk(selectionSet *ast.SelectionSet, parent *graphql.Object) (int, int) {
+	depth, complexity := 0, 0
+	if selectionSet == nil || parent == nil {
+		return depth, complexity
+	}
+	for _, selection := range selectionSet.Selections {
+		var selectionDepth, selectionComplexity int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			field, ok := parent.Fields()[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			child, _ := graphql.GetNamed(field.Type).(*graphql.Object)
+			childDepth, childComplexity := walker.walk(selection.SelectionSet, child)
+			selectionDepth = 1 + childDepth
+			selectionComplexity = capGraphqlCost(1 + walker.limit(field, selection)*childComplexity)
+		case *ast.InlineFragment:
+			selectionDepth, selectionComplexity = walker.walk(selection.SelectionSet, walker.fragmentType(selection.TypeCondition, parent))
+		case *ast.FragmentSpread:
+			fragment, ok := walker.fragments[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			selectionDepth, selectionComplexity = walker.walk(fragment.SelectionSet, walker.fragmentType(fragment.TypeCondition, parent))
+		}
+		if selectionDepth > depth {
+			depth = selectionDepth
+		}
+		complexity = capGraphqlCost(complexity + selectionComplexity)
+	}
+	return depth, complexity
+}
+
+// capGraphqlCost stops counting just past graphqlMaxComplexity. Every cost
+// stays that small, so multiplying by a limit can't overflow.
+func capGraphqlCost(complexity int) int {
+	if complexity > graphqlMaxComplexity {
+		return graphqlMaxComplexity + 1
+	}
+	return complexity
+}
+
+func (walker graphqlCostWalker) fragmentType(typeCondition *ast.Named, parent *graphql.Object) *graphql.Object {
+	if typeCondition == nil {
+		return parent
+	}
+	object, _ := walker.schema.Type(typeCondition.Name.Value).(*graphql.Object)
+	return object
+}
+
+// limit is how many items a list field asks for: its limit argument, or
+// that argument's default. Other fields count once. Limits the resolvers
+// would reject, or that can't be read, count as maxGraphqlListLimit.
+func (walker graphqlCostWalker) limit(field *graphql.FieldDefinition, selection *ast.Field) int {
+	limit := 1
+	for _, arg := range field.Args {
+		if arg.PrivateName == "limit" {
+			limit, _ = arg.DefaultValue.(int)
+		}
+	}
+	for _, arg := range selection.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		limit = maxGraphqlListLimit
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if parsed, err := strconv.Atoi(value.Value); err == nil {
+				limit = parsed
+			}
+		case *ast.Variable:
+			// Variables decoded from JSON are float64
+			if variable, ok := walker.variables[value.Name.Value].(float64); ok && variable >= 1 && variable <= maxGraphqlListLimit {
+				limit = int(variable)
+			}
+		}
+	}
+	if limit < 1 || limit > maxGraphqlListLimit {
+		return maxGraphqlListLimit
+	}
+	return limit
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// batchLoader collects the keys asked for while one level of a GraphQL query
+// is resolved and fetches them all at once the first time any of them is
+// needed, so a list of 20 recipes loads its authors with one query instead
+// of 20. Results are kept for the rest of the request.
+type batchLoader[T any] struct {
+	fetch   func(ctx context.Context, keys []string) (map[string]T, error)
+	mu      sync.Mutex
+	pending []string
+	queued  map[string]bool
+	results map[string]T
+	errs    map[string]error
+}
+
+func newBatchLoader[T any](fetch func(ctx context.Context, keys []string) (map[string]T, error)) *batchLoader[T] {
+	return &batchLoader[T]{fetch: fetch, queued: map[string]bool{}, results: map[string]T{}, errs: map[string]error{}}
+}
+
+// load queues key and returns a function that waits for it. graphql-go only
+// calls the function once every field at the current level has queued its
+// keys. ok is false when fetch found nothing for the key.
+func (loader *batchLoader[T]) load(ctx context.Context, key string) func() (value T, ok bool, err error) {
+	loader.mu.Lock()
+	if !loader.queued[key] {
+		loader.queued[key] = true
+		loader.pending = append(loader.pending, key)
+	}
+	loader.mu.Unlock()
+
+	return func() (T, bool, error) {
+		loader.mu.Lock()
+		defer loader.mu.Unlock()
+		if len(loader.pending) > 0 {
+			keys := loader.pending
+			loader.pending = nil
+			results, err := loader.fetch(ctx, keys)
+			for _, pendingKey := range keys {
+				if err != nil {
+					loader.errs[pendingKey] = err
+				} else if result, found := results[pendingKey]; found {
+					loader.results[pendingKey] = result
+				}
+			}
+		}
+		value, ok := loader.results[key]
+		return value, ok, loader.errs[key]
+	}
+}
+
+// listPage is the limit and offset a list field was asked for.
+type listPage struct {
+	limit, offset int
+}
+
+// pagedLoader is a batchLoader for lists that are paged per key, like a
+// recipe's reviews. Keys can only share a query when they ask for the same
+// page, so there's a batchLoader per page.
+type pagedLoader[T any] struct {
+	fetch   func(ctx context.Context, keys []string, page listPage) (map[string][]T, error)
+	mu      sync.Mutex
+	loaders map[listPage]*batchLoader[[]T]
+}
+
+func newPagedLoader[T any](fetch func(ctx context.Context, keys []string, page listPage) (map[string][]T, error)) *pagedLoader[T] {
+	return &pagedLoader[T]{fetch: fetch, loaders: map[listPage]*batchLoader[[]T]{}}
+}
+
+func (paged *pagedLoader[T]) load(ctx context.Context, key string, page listPage) func() ([]T, bool, error) {
+	paged.mu.Lock()
+	loader, ok := paged.loaders[page]
+	if !ok {
+		loader = newBatchLoader(func(ctx context.Context, keys []string) (map[string][]T, error) {
+			return paged.fetch(ctx, keys, page)
+		})
+		paged.loaders[page] = loader
+	}
+	paged.mu.Unlock()
+	return loader.load(ctx, key)
+}
+
+// pagedIds pages the ids of the documents matching filter for each value of
+// groupField among keys, in sort order. Only ids are grouped, the documents
+// themselves are fetched afterwards, so long lists can't outgrow a group.
+func pagedIds(ctx context.Context, collection *mongo.Collection, groupField string, keys []string, filter bson.M, sort bson.D, page listPage) (map[string][]string, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: andFilter([]bson.M{{groupField: bson.M{"$in": keys}}, filter})}},
+		{{Key: "$sort", Value: sort}},
+		{{Key: "$group", Value: bson.M{"_id": "$" + groupField, "ids": bson.M{"$push": "$_id"}}}},
+		{{Key: "$project", Value: bson.M{"ids": bson.M{"$slice": bson.A{"$ids", page.offset, page.limit}}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Key string   `bson:"_id"`
+		Ids []string `bson:"ids"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	byKey := map[string][]string{}
+	for _, group := range groups {
+		byKey[group.Key] = group.Ids
+	}
+	return byKey, nil
+}
+
+// graphqlLoaders are the batch loaders of one GraphQL request. Recipes are
+// loaded as the viewer, so ones they can't see are never found.
+type graphqlLoaders struct {
+	recipes     *batchLoader[models.Recipe]
+	authors     *batchLoader[models.Author]
+	reviews     *pagedLoader[models.Review]
+	userRecipes *pagedLoader[models.Recipe]
+	favorites   *batchLoader[[]string]
+}
+
+func newGraphqlLoaders(viewer *models.KeycloakUser) *graphqlLoaders {
+	return &graphqlLoaders{
+		recipes: newBatchLoader(func(ctx context.Context, recipeIds []string) (map[string]models.Recipe, error) {
+			recipes, err := findRecipes(ctx, andFilter([]bson.M{{"_id": bson.M{"$in": recipeIds}}, accessibleFilter(viewer)}), nil)
+			if err != nil {
+				return nil, err
+			}
+			byId := map[string]models.Recipe{}
+			for _, recipe := range recipes {
+				byId[recipe.Id] = recipe
+			}
+			return byId, nil
+		}),
+		authors: newBatchLoader(findAuthors),
+		reviews: newPagedLoader(func(ctx context.Context, recipeIds []string, page listPage) (map[string][]models.Review, error) {
+			sort := bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}}
+			idsByRecipe, err := pagedIds(ctx, reviewCollection, "recipeId", recipeIds, bson.M{}, sort, page)
+			if err != nil {
+				return nil, err
+			}
+			reviewIds := []string{}
+			for _, ids := range idsByRecipe {
+				reviewIds = append(reviewIds, ids...)
+			}
+			results, err := reviewCollection.Find(ctx, bson.M{"_id": bson.M{"$in": reviewIds}})
+			if err != nil {
+				return nil, err
+			}
+			var reviews []models.Review
+			if err = results.All(ctx, &reviews); err != nil {
+				return nil, err
+			}
+			byId := map[string]models.Review{}
+			for _, review := range reviews {
+				byId[review.Id] = review
+			}
+			byRecipe := map[string][]models.Review{}
+			for _, recipeId := range recipeIds {
+				byRecipe[recipeId] = []models.Review{}
+				for _, id := range idsByRecipe[recipeId] {
+					if review, ok := byId[id]; ok {
+						byRecipe[recipeId] = append(byRecipe[recipeId], review)
+					}
+				}
+			}
+			return byRecipe, nil
+		}),
+		userRecipes: newPagedLoader(func(ctx context.Context, authorIds []string, page listPage) (map[string][]models.Recipe, error) {
+			sort := bson.D{{Key: "publishedAt", Value: -1}, {Key: "_id", Value: -1}}
+			idsByAuthor, err := pagedIds(ctx, recipeCollection, "authorId", authorIds, discoverableFilter(viewer), sort, page)
+			if err != nil {
+				return nil, err
+			}
+			recipeIds := []string{}
+			for _, ids := range idsByAuthor {
+				recipeIds = append(recipeIds, ids...)
+			}
+			recipes, err := findRecipes(ctx, bson.M{"_id": bson.M{"$in": recipeIds}}, nil)
+			if err != nil {
+				return nil, err
+			}
+			byId := map[string]models.Recipe{}
+			for _, recipe := range recipes {
+				byId[recipe.Id] = recipe
+			}
+			byAuthor := map[string][]models.Recipe{}
+			for _, authorId := range authorIds {
+				byAuthor[authorId] = []models.Recipe{}
+				for _, id := range idsByAuthor[authorId] {
+					if recipe, ok := byId[id]; ok {
+						byAuthor[authorId] = append(byAuthor[authorId], recipe)
+					}
+				}
+			}
+			return byAuthor, nil
+		}),
+		favorites: newBatchLoader(func(ctx context.Context, userIds []string) (map[string][]string, error) {
+			results, err := usersCollection.Find(ctx, bson.M{"_id": bson.M{"$in": userIds}})
+			if err != nil {
+				return nil, err
+			}
+			var mongoUsers []models.MongoUser
+			if err = results.All(ctx, &mongoUsers); err != nil {
+				return nil, err
+			}
+			byUser := map[string][]string{}
+			for _, userId := range userIds {
+				byUser[userId] = []string{}
+			}
+			for _, mongoUser := range mongoUsers {
+				byUser[mongoUser.Id] = mongoUser.FavoriteRecipes
+			}
+			return byUser, nil
+		}),
+	}
+}
+
+// findAuthors loads the public profiles and stats of several authors, the
+// way GetAuthor does for one. Users with neither a profile nor public recipes
+// aren't authors and are left out.
+func findAuthors(ctx context.Context, authorIds []string) (map[string]models.Author, error) {
+	authors, err := authorsStats(ctx, authorIds)
+	if err != nil {
+		return nil, err
+	}
+	results, err := usersCollection.Find(ctx, bson.M{"_id": bson.M{"$in": authorIds}, "profile": bson.M{"$exists": true}})
+	if err != nil {
+		return nil, err
+	}
+	var mongoUsers []models.MongoUser
+	if err = results.All(ctx, &mongoUsers); err != nil {
+		return nil, err
+	}
+	hasProfile := map[string]bool{}
+	for _, mongoUser := range mongoUsers {
+		author := authors[mongoUser.Id]
+		author.DisplayName, author.Bio, author.AvatarUrl = mongoUser.Profile.DisplayName, mongoUser.Profile.Bio, mongoUser.Profile.AvatarUrl
+		authors[mongoUser.Id] = author
+		hasProfile[mongoUser.Id] = true
+	}
+	for authorId, author := range authors {
+		if !hasProfile[authorId] && author.RecipeCount == 0 {
+			delete(authors, authorId)
+		}
+	}
+	return authors, nil
+}
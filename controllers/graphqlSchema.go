@@ -0,0 +1,575 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"github.com/graphql-go/graphql"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultGraphqlListLimit = 20
+const defaultGraphqlReviewLimit = 10
+const maxGraphqlListLimit = 100
+
+type graphqlRequestKey struct{}
+
+// graphqlRequest is what resolvers know about the request they're part of.
+// viewerErr says why there's no viewer, for fields that need one.
+type graphqlRequest struct {
+	viewer    *models.KeycloakUser
+	viewerErr error
+	loaders   *graphqlLoaders
+}
+
+func graphqlRequestFrom(ctx context.Context) *graphqlRequest {
+	return ctx.Value(graphqlRequestKey{}).(*graphqlRequest)
+}
+
+// graphqlUser is requireUser for resolvers.
+func graphqlUser(ctx context.Context) (models.KeycloakUser, error) {
+	request := graphqlRequestFrom(ctx)
+	if request.viewer == nil {
+		return models.KeycloakUser{}, request.viewerErr
+	}
+	return *request.viewer, nil
+}
+
+// graphqlPage reads the limit and offset arguments of a list field.
+func graphqlPage(p graphql.ResolveParams) (int, int, error) {
+	limit, _ := p.Args["limit"].(int)
+	offset, _ := p.Args["offset"].(int)
+	if limit < 1 || limit > maxGraphqlListLimit {
+		return 0, 0, errors.New("limit must be between 1 and " + strconv.Itoa(maxGraphqlListLimit))
+	}
+	if offset < 0 {
+		return 0, 0, errors.New("offset cannot be negative")
+	}
+	return limit, offset, nil
+}
+
+// graphqlInput decodes an input object argument into the model it mirrors.
+// Input fields are named like the model's JSON fields.
+func graphqlInput(arg interface{}, model interface{}) error {
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, model)
+}
+
+// loadRecipe resolves to a recipe the viewer can see, or null.
+func loadRecipe(ctx context.Context, recipeId string) (interface{}, error) {
+	if recipeId == "" {
+		return nil, nil
+	}
+	load := graphqlRequestFrom(ctx).loaders.recipes.load(ctx, recipeId)
+	return func() (interface{}, error) {
+		recipe, ok, err := load()
+		if err != nil || !ok {
+			return nil, err
+		}
+		return recipe, nil
+	}, nil
+}
+
+// loadAuthor resolves to a user's public profile. Users who aren't authors
+// yet resolve to null unless known is set, when they still get their ID.
+func loadAuthor(ctx context.Context, userId string, known bool) (interface{}, error) {
+	load := graphqlRequestFrom(ctx).loaders.authors.load(ctx, userId)
+	return func() (interface{}, error) {
+		author, ok, err := load()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if !known {
+				return nil, nil
+			}
+			author = models.Author{Id: userId}
+		}
+		return author, nil
+	}, nil
+}
+
+// newGraphqlSchema builds the GraphQL schema. Resolvers go through the same
+// functions and visibility rules as the REST handlers.
+func newGraphqlSchema() (graphql.Schema, error) {
+	page := func(limit int) graphql.FieldConfigArgument {
+		return graphql.FieldConfigArgument{
+			"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: limit},
+			"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+		}
+	}
+	stringList := graphql.NewList(graphql.NewNonNull(graphql.String))
+
+	stepTimerType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "StepTimer",
+		Fields: graphql.Fields{
+			"text":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"duration":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"maxDuration": &graphql.Field{Type: graphql.String},
+		},
+	})
+	stepType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Step",
+		Fields: graphql.Fields{
+			"section":        &graphql.Field{Type: graphql.String},
+			"text":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"timer":          &graphql.Field{Type: graphql.String},
+			"imageLink":      &graphql.Field{Type: graphql.String},
+			"ingredients":    &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+			"detectedTimers": &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(stepTimerType))},
+		},
+	})
+	yieldType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Yield",
+		Fields: graphql.Fields{
+			"quantity": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"unit":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	nutritionFactsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "NutritionFacts",
+		Fields: graphql.Fields{
+			"calories":      &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"protein":       &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"fat":           &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"carbohydrates": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"fiber":         &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"sugar":         &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"sodium":        &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+	nutritionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Nutrition",
+		Fields: graphql.Fields{
+			"total":        &graphql.Field{Type: graphql.NewNonNull(nutritionFactsType)},
+			"perServing":   &graphql.Field{Type: graphql.NewNonNull(nutritionFactsType)},
+			"servings":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"confidence":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"calculatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	var recipeType, userType, reviewType *graphql.Object
+	recipeType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Recipe",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"title":       &graphql.Field{Type: graphql.String},
+				"ingredients": &graphql.Field{Type: stringList},
+				"instructions": &graphql.Field{
+					Type: graphql.NewList(graphql.NewNonNull(stepType)),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						recipe := p.Source.(models.Recipe)
+						// Recipes saved before timers were detected still get them
+						annotateSteps(recipe.Instructions)
+						return recipe.Instructions, nil
+					},
+				},
+				"imageLinks":          &graphql.Field{Type: graphql.String},
+				"servings":            &graphql.Field{Type: graphql.Int},
+				"nutrition":           &graphql.Field{Type: nutritionType},
+				"dietaryTags":         &graphql.Field{Type: stringList},
+				"detectedDietaryTags": &graphql.Field{Type: stringList},
+				"allergens":           &graphql.Field{Type: stringList},
+				"cuisine":             &graphql.Field{Type: graphql.String},
+				"course":              &graphql.Field{Type: graphql.String},
+				"difficulty":          &graphql.Field{Type: graphql.String},
+				"yield":               &graphql.Field{Type: yieldType},
+				"prepTime":            &graphql.Field{Type: graphql.String},
+				"cookTime":            &graphql.Field{Type: graphql.String},
+				"totalTime":           &graphql.Field{Type: graphql.String},
+				"totalTimeMinutes":    &graphql.Field{Type: graphql.Int},
+				"averageRating":       &graphql.Field{Type: graphql.Float},
+				"ratingCount":         &graphql.Field{Type: graphql.Int},
+				"visibility":          &graphql.Field{Type: graphql.String},
+				"status":              &graphql.Field{Type: graphql.String},
+				"publishedAt":         &graphql.Field{Type: graphql.DateTime},
+				"archivedAt":          &graphql.Field{Type: graphql.DateTime},
+				"author": &graphql.Field{
+					Type: graphql.NewNonNull(userType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadAuthor(p.Context, p.Source.(models.Recipe).AuthorId, true)
+					},
+				},
+				"parent": &graphql.Field{
+					Type:        recipeType,
+					Description: "The recipe this one was forked from, if the viewer can still see it.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadRecipe(p.Context, p.Source.(models.Recipe).ParentRecipeId)
+					},
+				},
+				"reviews": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(reviewType))),
+					Description: "Newest first.",
+					Args:        page(defaultGraphqlReviewLimit),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						limit, offset, err := graphqlPage(p)
+						if err != nil {
+							return nil, err
+						}
+						load := graphqlRequestFrom(p.Context).loaders.reviews.load(p.Context, p.Source.(models.Recipe).Id, listPage{limit: limit, offset: offset})
+						return func() (interface{}, error) {
+							reviews, _, err := load()
+							return reviews, err
+						}, nil
+					},
+				},
+				"favorited": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Boolean),
+					Description: "Whether the viewer has favorited the recipe, always false when anonymous.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						request := graphqlRequestFrom(p.Context)
+						if request.viewer == nil {
+							return false, nil
+						}
+						recipeId := p.Source.(models.Recipe).Id
+						load := request.loaders.favorites.load(p.Context, request.viewer.Sub)
+						return func() (interface{}, error) {
+							favorites, _, err := load()
+							if err != nil {
+								return nil, err
+							}
+							for _, id := range favorites {
+								if id == recipeId {
+									return true, nil
+								}
+							}
+							return false, nil
+						}, nil
+					},
+				},
+			}
+		}),
+	})
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "User",
+		Description: "A user's public profile, with stats over their public recipes.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":            &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"displayName":   &graphql.Field{Type: graphql.String},
+				"bio":           &graphql.Field{Type: graphql.String},
+				"avatarUrl":     &graphql.Field{Type: graphql.String},
+				"recipeCount":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"averageRating": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+				"ratingCount":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"followerCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"recipes": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(recipeType))),
+					Description: "Newest first. Users see their own drafts and private recipes too.",
+					Args:        page(defaultGraphqlListLimit),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						limit, offset, err := graphqlPage(p)
+						if err != nil {
+							return nil, err
+						}
+						load := graphqlRequestFrom(p.Context).loaders.userRecipes.load(p.Context, p.Source.(models.Author).Id, listPage{limit: limit, offset: offset})
+						return func() (interface{}, error) {
+							recipes, _, err := load()
+							return recipes, err
+						}, nil
+					},
+				},
+				"favorites": &graphql.Field{
+					Type:        graphql.NewList(graphql.NewNonNull(recipeType)),
+					Description: "Only users themselves can see their favorites.",
+					Args:        page(defaultGraphqlListLimit),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						limit, offset, err := graphqlPage(p)
+						if err != nil {
+							return nil, err
+						}
+						keycloakUser, err := graphqlUser(p.Context)
+						if err != nil {
+							return nil, err
+						}
+						userId := p.Source.(models.Author).Id
+						if keycloakUser.Sub != userId {
+							return nil, newServiceError(http.StatusForbidden, "token does not belong to user with ID "+userId)
+						}
+						favorites, err := favoriteRecipeIds(p.Context, userId)
+						if err != nil {
+							return nil, err
+						}
+						// Favorites the author has since made private drop out
+						filter := andFilter([]bson.M{{"_id": bson.M{"$in": favorites}}, accessibleFilter(&keycloakUser)})
+						return findRecipes(p.Context, filter, options.Find().SetSort(bson.M{"_id": -1}).SetSkip(int64(offset)).SetLimit(int64(limit)))
+					},
+				},
+			}
+		}),
+	})
+
+	reviewType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Review",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"rating":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"comment":   &graphql.Field{Type: graphql.String},
+				"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"recipe": &graphql.Field{
+					Type: recipeType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadRecipe(p.Context, p.Source.(models.Review).RecipeId)
+					},
+				},
+				"user": &graphql.Field{
+					Type: graphql.NewNonNull(userType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadAuthor(p.Context, p.Source.(models.Review).UserId, true)
+					},
+				},
+			}
+		}),
+	})
+
+	recipeSearchType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "RecipeSearch",
+		Description: "The filters of POST /recipes/search.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"searchTerm":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"includeIngredients": &graphql.InputObjectFieldConfig{Type: stringList},
+			"excludeIngredients": &graphql.InputObjectFieldConfig{Type: stringList},
+			"maxTotalTime":       &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"cuisine":            &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"course":             &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"difficulty":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"dietaryTags":        &graphql.InputObjectFieldConfig{Type: stringList},
+			"excludeAllergens":   &graphql.InputObjectFieldConfig{Type: stringList},
+			"minRating":          &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"authorId":           &graphql.InputObjectFieldConfig{Type: graphql.ID},
+		},
+	})
+	stepInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "StepInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"section":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"text":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"timer":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"imageLink":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"ingredients": &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+		},
+	})
+	yieldInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "YieldInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"quantity": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+			"unit":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	recipeInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "RecipeInput",
+		Description: "The fields of a recipe its author and editors control.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"ingredients":  &graphql.InputObjectFieldConfig{Type: stringList},
+			"instructions": &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(stepInputType))},
+			"imageLinks":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"servings":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"dietaryTags":  &graphql.InputObjectFieldConfig{Type: stringList},
+			"cuisine":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"course":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"difficulty":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"yield":        &graphql.InputObjectFieldConfig{Type: yieldInputType},
+			"prepTime":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"cookTime":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"totalTime":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"visibility":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"recipe": &graphql.Field{
+				Type: recipeType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadRecipe(p.Context, p.Args["id"].(string))
+				},
+			},
+			"recipes": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(recipeType))),
+				Description: "Recipes matching the search, newest first. Signed in users who opted in get their preferences applied unless applyPreferences is false.",
+				Args: graphql.FieldConfigArgument{
+					"search":           &graphql.ArgumentConfig{Type: recipeSearchType},
+					"applyPreferences": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: true},
+					"limit":            &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphqlListLimit},
+					"offset":           &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit, offset, err := graphqlPage(p)
+					if err != nil {
+						return nil, err
+					}
+					var searchQuery models.SearchQuery
+					if search, ok := p.Args["search"]; ok {
+						if err := graphqlInput(search, &searchQuery); err != nil {
+							return nil, err
+						}
+					}
+					if err := validateSearchQuery(searchQuery); err != nil {
+						return nil, err
+					}
+					applyPreferences, _ := p.Args["applyPreferences"].(bool)
+					filter, err := listingFilter(p.Context, graphqlRequestFrom(p.Context).viewer, applyPreferences, searchConditions(searchQuery))
+					if err != nil {
+						return nil, err
+					}
+					return findRecipes(p.Context, filter, options.Find().SetSort(bson.M{"_id": -1}).SetSkip(int64(offset)).SetLimit(int64(limit)))
+				},
+			},
+			"user": &graphql.Field{
+				Type:        userType,
+				Description: "A user's public profile, or null for users with neither a profile nor public recipes.",
+				Args:        graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadAuthor(p.Context, p.Args["id"].(string), false)
+				},
+			},
+			"me": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					keycloakUser, err := graphqlUser(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					return loadAuthor(p.Context, keycloakUser.Sub, true)
+				},
+			},
+		},
+	})
+
+	recipeIdArgs := graphql.FieldConfigArgument{"recipeId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}}
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createRecipe": &graphql.Field{
+				Type: graphql.NewNonNull(recipeType),
+				Args: graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(recipeInputType)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					keycloakUser, err := graphqlUser(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					var recipe models.Recipe
+					if err := graphqlInput(p.Args["input"], &recipe); err != nil {
+						return nil, err
+					}
+					return createRecipe(p.Context, keycloakUser, recipe)
+				},
+			},
+			"updateRecipe": &graphql.Field{
+				Type:        graphql.NewNonNull(recipeType),
+				Description: "Replaces the recipe's content like PUT /recipes/:id, so fields left out are cleared.",
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(recipeInputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					keycloakUser, err := graphqlUser(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					var changes models.Recipe
+					if err := graphqlInput(p.Args["input"], &changes); err != nil {
+						return nil, err
+					}
+					return updateRecipe(p.Context, keycloakUser, p.Args["id"].(string), changes)
+				},
+			},
+			"deleteRecipe": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					keycloakUser, err := graphqlUser(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					result, err := deleteRecipe(p.Context, keycloakUser, p.Args["id"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return result.DeletedCount > 0, nil
+				},
+			},
+			"reviewRecipe": &graphql.Field{
+				Type: graphql.NewNonNull(reviewType),
+				Args: graphql.FieldConfigArgument{
+					"recipeId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"rating":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"comment":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					keycloakUser, err := graphqlUser(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					comment, _ := p.Args["comment"].(string)
+					return reviewRecipe(p.Context, keycloakUser, p.Args["recipeId"].(string), models.ReviewRequest{Rating: p.Args["rating"].(int), Comment: comment})
+				},
+			},
+			"deleteReview": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: recipeIdArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					keycloakUser, err := graphqlUser(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					result, err := deleteReview(p.Context, keycloakUser, p.Args["recipeId"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return result.DeletedCount > 0, nil
+				},
+			},
+			"favoriteRecipe": &graphql.Field{
+				Type: graphql.NewNonNull(recipeType),
+				Args: recipeIdArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					keycloakUser, err := graphqlUser(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					recipeId := p.Args["recipeId"].(string)
+					if _, err := setFavorite(p.Context, keycloakUser, recipeId, true); err != nil {
+						return nil, err
+					}
+					return viewableRecipe(p.Context, recipeId, &keycloakUser)
+				},
+			},
+			"unfavoriteRecipe": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Whether the viewer's favorites changed.",
+				Args:        recipeIdArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					keycloakUser, err := graphqlUser(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					result, err := setFavorite(p.Context, keycloakUser, p.Args["recipeId"].(string), false)
+					if err != nil {
+						return nil, err
+					}
+					return result.ModifiedCount > 0, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType, Mutation: mutationType})
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/jobs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func PostJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var jobRequest models.JobRequest
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&jobRequest); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if !jobs.IsRegistered(jobRequest.Type) || jobs.IsInternal(jobRequest.Type) {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "unknown job type '" + jobRequest.Type + "'"}})
+			return
+		}
+
+		log.Println("Queueing job of type ", jobRequest.Type, " for user with ID ", keycloakUser.Sub)
+		job, err := jobs.Enqueue(ctx, jobRequest.Type, keycloakUser.Sub, jobRequest.Payload)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		addJobLinks(job)
+		c.Header("Location", job.Links["self"])
+		c.JSON(http.StatusAccepted, responses.RecipeResponse{Status: http.StatusAccepted, Message: "Successfully queued job!", Data: map[string]interface{}{"data": job}})
+	}
+}
+
+func GetJobs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userJobs, err := jobs.FindByOwner(ctx, keycloakUser.Sub)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		for i := range userJobs {
+			addJobLinks(&userJobs[i])
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched all jobs!", Data: map[string]interface{}{"data": userJobs}})
+	}
+}
+
+func GetJobById() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, ok := findOwnedJob(c)
+		if !ok {
+			return
+		}
+		addJobLinks(job)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched job with ID " + job.Id, Data: map[string]interface{}{"data": job}})
+	}
+}
+
+// GetJobResult returns what a finished job produced. Exports come with the
+// recipes they saved.
+func GetJobResult() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, ok := findOwnedJob(c)
+		if !ok {
+			return
+		}
+		if job.Status != models.JobStatusSucceeded {
+			c.JSON(http.StatusConflict, responses.RecipeResponse{Status: http.StatusConflict, Message: "error", Data: map[string]interface{}{"data": "job with ID " + job.Id + " has status " + job.Status}})
+			return
+		}
+		result := job.Result
+		if job.Type == JobTypeRecipeExport {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			recipes, err := exportedRecipes(ctx, job.Id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+				return
+			}
+			result = map[string]interface{}{"count": job.Result["count"], "recipes": recipes}
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched result of job with ID " + job.Id, Data: map[string]interface{}{"data": result}})
+	}
+}
+
+func CancelJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, ok := findOwnedJob(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		log.Println("Cancelling job with ID ", job.Id)
+		job, err := jobs.Cancel(ctx, job.Id)
+		if err == jobs.ErrJobFinished {
+			c.JSON(http.StatusConflict, responses.RecipeResponse{Status: http.StatusConflict, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		addJobLinks(job)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully requested cancellation of job with ID " + job.Id, Data: map[string]interface{}{"data": job}})
+	}
+}
+
+// findOwnedJob loads the job named by the :id param and makes sure it belongs
+// to the calling user. It writes the error response itself when it fails.
+func findOwnedJob(c *gin.Context) (*models.Job, bool) {
+	keycloakUser, ok := requireUser(c)
+	if !ok {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := jobs.FindById(ctx, c.Param("id"))
+	if err == mongo.ErrNoDocuments || (err == nil && job.OwnerId != keycloakUser.Sub) {
+		c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no job with ID " + c.Param("id")}})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+		return nil, false
+	}
+	return job, true
+}
+
+func addJobLinks(job *models.Job) {
+	self := "/api/v1/jobs/" + job.Id
+	job.Links = map[string]string{"self": self}
+	switch job.Status {
+	case models.JobStatusQueued, models.JobStatusRunning:
+		job.Links["cancel"] = self + "/cancel"
+	case models.JobStatusSucceeded:
+		job.Links["result"] = self + "/result"
+	}
+}
@@ -0,0 +1,298 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Streams look for new events this often even when nothing in this process
+// said there are any, to pick up changes made through other copies of the
+// API.
+const livePollInterval = 2 * time.Second
+
+// An idle stream gets a comment this often so proxies don't close it.
+const liveKeepAliveInterval = 15 * time.Second
+
+// Recipe events are kept this long for clients resuming with Last-Event-ID.
+const recipeEventRetention = 24 * time.Hour
+
+const liveBatchSize = 100
+
+const feedTopic = "feed"
+
+// The sequences recipe and feed events are numbered by.
+const (
+	recipeEventSequence = "recipeEvents"
+	feedEventSequence   = "feedEvents"
+)
+
+var recipeEventCollection *mongo.Collection = configs.GetCollection(configs.DB, "recipeEvents")
+
+// liveUpdatesFromEvents is set once recipe updates are recorded from the
+// event bus, like recipeWebhooksFromEvents.
+var liveUpdatesFromEvents bool
+
+// liveHub wakes up the streams waiting on a topic when something new is
+// recorded for it.
+type liveHub struct {
+	mu     sync.Mutex
+	topics map[string]chan struct{}
+	closed chan struct{}
+}
+
+var liveStreams = &liveHub{topics: map[string]chan struct{}{}, closed: make(chan struct{})}
+
+// wait returns a channel that is closed the next time the topic is
+// notified.
+func (h *liveHub) wait(topic string) <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch, ok := h.topics[topic]
+	if !ok {
+		ch = make(chan struct{})
+		h.topics[topic] = ch
+	}
+	return ch
+}
+
+func (h *liveHub) notify(topic string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ch, ok := h.topics[topic]; ok {
+		close(ch)
+		delete(h.topics, topic)
+	}
+}
+
+// CloseLiveStreams ends every open event stream, gRPC ListChanges streams
+// included, so shutting down the servers doesn't wait on them.
+func CloseLiveStreams() {
+	liveStreams.mu.Lock()
+	defer liveStreams.mu.Unlock()
+	select {
+	case <-liveStreams.closed:
+	default:
+		close(liveStreams.closed)
+	}
+}
+
+// EnsureLiveIndexes creates the indexes used to resume recipe streams and
+// expire old recipe events.
+func EnsureLiveIndexes(ctx context.Context) error {
+	_, err := recipeEventCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "recipeId", Value: 1}, {Key: "sequence", Value: 1}}},
+		{Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(recipeEventRetention.Seconds()))},
+	})
+	return err
+}
+
+// StreamRecipeEvents pushes updates and reviews of a recipe as server-sent
+// events to anyone who can see it. Clients that reconnect with the
+// Last-Event-ID header, or ?lastEventId= since EventSource can't set headers
+// on the first connection, get what they missed in the meantime.
+func StreamRecipeEvents() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		viewer := optionalUser(c)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), viewer)
+		cancel()
+		if !ok {
+			return
+		}
+
+		since, ok := resumeSequence(c, recipeEventSequence)
+		if !ok {
+			return
+		}
+		topic := "recipe:" + recipe.Id
+		streamEvents(c, topic, func(ctx context.Context) ([]sse.Event, bool, error) {
+			filter := bson.M{"recipeId": recipe.Id, "sequence": bson.M{"$gt": since}}
+			results, err := recipeEventCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"sequence": 1}).SetLimit(liveBatchSize))
+			if err != nil {
+				return nil, false, err
+			}
+			var recipeEvents []models.RecipeEvent
+			if err = results.All(ctx, &recipeEvents); err != nil {
+				return nil, false, err
+			}
+			var batch []sse.Event
+			for _, event := range recipeEvents {
+				since = event.Sequence
+				// Stop streaming to anyone who lost access to the recipe
+				if event.Recipe != nil && !canView(*event.Recipe, viewer) {
+					return batch, false, nil
+				}
+				batch = append(batch, sse.Event{Id: strconv.FormatInt(event.Sequence, 10), Event: event.Type, Data: event})
+			}
+			return batch, true, nil
+		})
+	}
+}
+
+// StreamUserFeed pushes new events in a user's feed as server-sent events,
+// resuming from Last-Event-ID like StreamRecipeEvents.
+func StreamUserFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		since, ok := resumeSequence(c, feedEventSequence)
+		if !ok {
+			return
+		}
+
+		userId := c.Param("id")
+		streamEvents(c, feedTopic, func(ctx context.Context) ([]sse.Event, bool, error) {
+			following, err := followedAuthorIds(ctx, userId)
+			if err != nil {
+				return nil, false, err
+			}
+			filter := feedFilter(userId, following)
+			filter["sequence"] = bson.M{"$gt": since}
+			results, err := feedCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"sequence": 1}).SetLimit(liveBatchSize))
+			if err != nil {
+				return nil, false, err
+			}
+			var feedEvents []models.FeedEvent
+			if err = results.All(ctx, &feedEvents); err != nil {
+				return nil, false, err
+			}
+			visible, err := discoverableRecipeIds(ctx, feedEvents)
+			if err != nil {
+				return nil, false, err
+			}
+			var batch []sse.Event
+			for _, event := range feedEvents {
+				since = event.Sequence
+				if event.Type == models.FeedReviewCreated || visible[event.RecipeId] {
+					batch = append(batch, sse.Event{Id: strconv.FormatInt(event.Sequence, 10), Event: event.Type, Data: event})
+				}
+			}
+			return batch, true, nil
+		})
+	}
+}
+
+// streamEvents writes the batches fetch returns as server-sent events until
+// the client goes away, fetch says to stop, or the server shuts down. fetch
+// is called again straight away after a full batch, otherwise once the
+// topic is notified or the poll interval passes.
+func streamEvents(c *gin.Context, topic string, fetch func(ctx context.Context) ([]sse.Event, bool, error)) {
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	lastWrite := time.Now()
+	c.Stream(func(w io.Writer) bool {
+		// Ask to be woken before fetching, so nothing recorded in between
+		// is missed
+		wake := liveStreams.wait(topic)
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		batch, more, err := fetch(ctx)
+		cancel()
+		if err != nil {
+			log.Println("Error streaming ", topic, ": ", err)
+			return false
+		}
+		for _, event := range batch {
+			c.Render(-1, event)
+		}
+		if len(batch) > 0 {
+			lastWrite = time.Now()
+		}
+		if !more {
+			return false
+		}
+		if len(batch) == liveBatchSize {
+			return true
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-liveStreams.closed:
+			return false
+		case <-wake:
+		case <-time.After(livePollInterval):
+		}
+		if time.Since(lastWrite) >= liveKeepAliveInterval {
+			io.WriteString(w, ": keep-alive\n\n")
+			lastWrite = time.Now()
+		}
+		return true
+	})
+}
+
+func lastEventId(c *gin.Context) string {
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.Query("lastEventId")
+}
+
+// resumeSequence is where a stream picks up: after the client's
+// Last-Event-ID, or after the newest event of the sequence for new streams.
+// It writes the error response itself.
+func resumeSequence(c *gin.Context, name string) (int64, bool) {
+	if id := lastEventId(c); id != "" {
+		sequence, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || sequence < 0 {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "Last-Event-ID must be the id of an event from this stream"}})
+			return 0, false
+		}
+		return sequence, true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sequence, err := currentSequence(ctx, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+		return 0, false
+	}
+	return sequence, true
+}
+
+// recordRecipeUpdate pushes the recipe as it is now to its live streams,
+// unless the event bus is already taking care of it.
+func recordRecipeUpdate(ctx context.Context, recipe models.Recipe, actorId string) {
+	if liveUpdatesFromEvents {
+		return
+	}
+	recordLiveRecipeEvent(ctx, models.RecipeEvent{RecipeId: recipe.Id, Type: models.RecipeEventUpdate, ActorId: actorId, Recipe: &recipe})
+}
+
+// recordLiveRecipeEvent saves an event for a recipe's live streams and wakes
+// them up. Streams are a side effect of the request, so failures are logged
+// rather than returned.
+func recordLiveRecipeEvent(ctx context.Context, event models.RecipeEvent) {
+	var err error
+	if event.Sequence, err = nextSequence(ctx, recipeEventSequence); err != nil {
+		log.Println("Error numbering ", event.Type, " event for recipe with ID ", event.RecipeId, ": ", err)
+		return
+	}
+	event.Id = primitive.NewObjectID().Hex()
+	event.CreatedAt = time.Now().UTC()
+	if _, err = recipeEventCollection.InsertOne(ctx, event); err != nil {
+		log.Println("Error recording ", event.Type, " event for recipe with ID ", event.RecipeId, ": ", err)
+		return
+	}
+	liveStreams.notify("recipe:" + event.RecipeId)
+}
@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/jobs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/notifications"
+	"github.com/hopk8412/table-recipes-api/responses"
+	"github.com/hopk8412/table-recipes-api/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultNotificationLimit = 20
+const maxNotificationLimit = 100
+
+var notificationCollection *mongo.Collection = configs.GetCollection(configs.DB, "notifications")
+
+// EnsureNotificationIndexes creates the indexes used to page through inboxes
+// and count unread notifications.
+func EnsureNotificationIndexes(ctx context.Context) error {
+	_, err := notificationCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "recipientId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "recipientId", Value: 1}, {Key: "read", Value: 1}}},
+	})
+	return err
+}
+
+// GetNotifications pages through a user's inbox, newest first, along with
+// how many notifications are unread. ?unread=true leaves out the ones already
+// read. Pass the nextBefore of one page as ?before= to get the next.
+func GetNotifications() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultNotificationLimit)))
+		if err != nil || limit < 1 || limit > maxNotificationLimit {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "limit must be between 1 and " + strconv.Itoa(maxNotificationLimit)}})
+			return
+		}
+		before := time.Now().UTC()
+		if c.Query("before") != "" {
+			if before, err = time.Parse(time.RFC3339Nano, c.Query("before")); err != nil {
+				c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "before must be an RFC 3339 timestamp"}})
+				return
+			}
+		}
+
+		filter := bson.M{"recipientId": c.Param("id"), "createdAt": bson.M{"$lt": before}}
+		if c.Query("unread") == "true" {
+			filter["read"] = false
+		}
+		inbox := []models.Notification{}
+		findOptions := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(int64(limit))
+		results, err := notificationCollection.Find(ctx, filter, findOptions)
+		if err == nil {
+			err = results.All(ctx, &inbox)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		unreadCount, err := notificationCollection.CountDocuments(ctx, bson.M{"recipientId": c.Param("id"), "read": false})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		var nextBefore string
+		if len(inbox) == limit {
+			nextBefore = inbox[len(inbox)-1].CreatedAt.Format(time.RFC3339Nano)
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched notifications for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": inbox, "unreadCount": unreadCount, "nextBefore": nextBefore}})
+	}
+}
+
+func MarkNotificationRead() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var notification models.Notification
+		err := notificationCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": c.Param("notificationId"), "recipientId": c.Param("id")},
+			bson.M{"$set": bson.M{"read": true, "readAt": time.Now().UTC()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&notification)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no notification with ID " + c.Param("notificationId")}})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully marked notification with ID " + notification.Id + " as read", Data: map[string]interface{}{"data": notification}})
+	}
+}
+
+func MarkAllNotificationsRead() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := notificationCollection.UpdateMany(ctx,
+			bson.M{"recipientId": c.Param("id"), "read": false},
+			bson.M{"$set": bson.M{"read": true, "readAt": time.Now().UTC()}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully marked notifications for user with ID " + c.Param("id") + " as read", Data: map[string]interface{}{"data": result}})
+	}
+}
+
+// GetNotificationPreferences returns the user's channels and the channels
+// the server has available.
+func GetNotificationPreferences() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		preferences, err := findNotificationPreferences(ctx, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched notification preferences for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": preferences, "availableChannels": notifications.Names()}})
+	}
+}
+
+// UpdateNotificationPreferences replaces the user's channels. Email only
+// goes to the user's verified Keycloak email, which is used whether or not
+// the request gives it. A new
+// webhook URL gets a new signing secret, which is in the response.
+func UpdateNotificationPreferences() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireSelf(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var preferences models.NotificationPreferences
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&preferences); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		preferences.Email, preferences.WebhookUrl = strings.TrimSpace(preferences.Email), strings.TrimSpace(preferences.WebhookUrl)
+		// Mail only goes to the address Keycloak verified, so nobody can have
+		// notifications sent to someone else
+		if preferences.Email != "" && (!keycloakUser.EmailVerified || !strings.EqualFold(preferences.Email, keycloakUser.Email)) {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "email must be the verified email address of your account"}})
+			return
+		}
+		if keycloakUser.EmailVerified {
+			preferences.Email = keycloakUser.Email
+		}
+		saved, err := findNotificationPreferences(ctx, keycloakUser.Sub)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if preferences.WebhookSecret, err = webhookSecret(saved, preferences.WebhookUrl); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if err := validateNotificationPreferences(&preferences); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		log.Println("Saving notification preferences for user with ID ", keycloakUser.Sub)
+		_, err = usersCollection.UpdateOne(ctx, bson.M{"_id": keycloakUser.Sub}, bson.M{"$set": bson.M{"notifications": preferences}}, options.Update().SetUpsert(true))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully updated notification preferences for user with ID " + keycloakUser.Sub, Data: map[string]interface{}{"data": preferences}})
+	}
+}
+
+// webhookSecret keeps the secret the user already has for webhookUrl, and
+// makes a new one when the URL changed. Secrets never come from the request.
+func webhookSecret(saved models.NotificationPreferences, webhookUrl string) (string, error) {
+	if webhookUrl == "" {
+		return "", nil
+	}
+	if saved.WebhookUrl == webhookUrl && saved.WebhookSecret != "" {
+		return saved.WebhookSecret, nil
+	}
+	return webhooks.NewSecret()
+}
+
+func validateNotificationPreferences(preferences *models.NotificationPreferences) error {
+	channels := []string{}
+	for _, name := range preferences.Channels {
+		name = strings.ToLower(strings.TrimSpace(name))
+		channel, err := notifications.Lookup(name)
+		if err != nil {
+			return errors.New("channels must be from " + strings.Join(notifications.Names(), ", ") + ", '" + name + "' isn't available")
+		}
+		if err = channel.Validate(*preferences); err != nil {
+			return err
+		}
+		channels = append(channels, name)
+	}
+	preferences.Channels = channels
+	return nil
+}
+
+// findNotificationPreferences returns empty preferences, just the inbox, for
+// users who never saved any.
+func findNotificationPreferences(ctx context.Context, userId string) (models.NotificationPreferences, error) {
+	var mongoUser models.MongoUser
+	err := usersCollection.FindOne(ctx, bson.M{"_id": userId}).Decode(&mongoUser)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return models.NotificationPreferences{}, err
+	}
+	if mongoUser.Notifications == nil {
+		return models.NotificationPreferences{Channels: []string{}}, nil
+	}
+	return *mongoUser.Notifications, nil
+}
+
+// notify puts a notification in the recipient's inbox and queues its
+// delivery through their other channels. Nobody is notified about their own
+// actions, and a notification with an Id that was already used is dropped.
+// Notifications are a side effect of the request, so failures are logged
+// rather than returned.
+func notify(ctx context.Context, notification models.Notification) {
+	if notification.RecipientId == "" || notification.RecipientId == notification.ActorId {
+		return
+	}
+	if notification.Id == "" {
+		notification.Id = primitive.NewObjectID().Hex()
+	}
+	notification.CreatedAt = time.Now().UTC()
+	if _, err := notificationCollection.InsertOne(ctx, notification); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			log.Println("Error saving ", notification.Type, " notification for user with ID ", notification.RecipientId, ": ", err)
+		}
+		return
+	}
+	if _, err := jobs.Enqueue(ctx, JobTypeNotificationDelivery, "", map[string]interface{}{"notificationId": notification.Id}); err != nil {
+		log.Println("Error queueing delivery of notification with ID ", notification.Id, ": ", err)
+	}
+}
+
+// notifyFavorite tells an author their recipe was favorited. Favoriting the
+// same recipe again after removing it doesn't notify them twice.
+func notifyFavorite(ctx context.Context, keycloakUser models.KeycloakUser, recipe models.Recipe) {
+	notify(ctx, models.Notification{
+		Id:          models.NotificationRecipeFavorited + ":" + keycloakUser.Sub + ":" + recipe.Id,
+		Type:        models.NotificationRecipeFavorited,
+		RecipientId: recipe.AuthorId,
+		ActorId:     keycloakUser.Sub,
+		ActorName:   keycloakUser.PreferredUsername,
+		RecipeId:    recipe.Id,
+		RecipeTitle: recipe.Title,
+	})
+}
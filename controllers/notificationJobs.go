@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hopk8412/table-recipes-api/jobs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/notifications"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/exp/slices"
+)
+
+const JobTypeNotificationDelivery = "notifications.deliver"
+
+// RegisterNotificationJobs lets the job queue deliver notifications, so
+// slow mail servers and webhooks never hold up a request.
+func RegisterNotificationJobs() {
+	jobs.RegisterInternal(JobTypeNotificationDelivery, deliverNotificationJob)
+}
+
+// deliverNotificationJob sends the payload's "notificationId" through each
+// channel the recipient turned on. Channels that succeed are remembered, so
+// when another channel fails only that one is tried again on retry.
+func deliverNotificationJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (map[string]interface{}, error) {
+	notificationId, _ := job.Payload["notificationId"].(string)
+	var notification models.Notification
+	if err := notificationCollection.FindOne(ctx, bson.M{"_id": notificationId}).Decode(&notification); err != nil {
+		return nil, err
+	}
+	preferences, err := findNotificationPreferences(ctx, notification.RecipientId)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []string
+	for i, name := range preferences.Channels {
+		if slices.Contains(notification.DeliveredTo, name) {
+			continue
+		}
+		channel, err := notifications.Lookup(name)
+		if err != nil {
+			// The channel has been turned off on the server since the
+			// user picked it, nothing to retry.
+			log.Println("Skipping ", name, " for notification with ID ", notification.Id, ": ", err)
+			continue
+		}
+		if err = channel.Send(ctx, preferences, notification); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		notification.DeliveredTo = append(notification.DeliveredTo, name)
+		if _, err = notificationCollection.UpdateByID(ctx, notification.Id, bson.M{"$addToSet": bson.M{"deliveredTo": name}}); err != nil {
+			return nil, err
+		}
+		progress((i + 1) * 100 / len(preferences.Channels))
+	}
+	if len(failures) > 0 {
+		return nil, errors.New(strings.Join(failures, "; "))
+	}
+	return map[string]interface{}{"notificationId": notification.Id, "deliveredTo": notification.DeliveredTo}, nil
+}
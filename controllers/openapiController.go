@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/hopk8412/table-recipes-api/openapi"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// swaggerInitializer replaces the one bundled with Swagger UI, which opens
+// the petstore example.
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "../openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// OpenAPISpec serves the OpenAPI document describing the REST API.
+func OpenAPISpec() gin.HandlerFunc {
+	spec, err := openapi.Spec()
+	if err != nil {
+		log.Fatal("Error building OpenAPI document: ", err)
+	}
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	}
+}
+
+// SwaggerUI serves Swagger UI, bundled into the binary, pointed at
+// OpenAPISpec.
+func SwaggerUI() gin.HandlerFunc {
+	files := http.FS(swaggerFiles.FS)
+	return func(c *gin.Context) {
+		filepath := strings.TrimPrefix(c.Param("filepath"), "/")
+		if filepath == "swagger-initializer.js" {
+			c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(swaggerInitializer))
+			return
+		}
+		c.FileFromFS(filepath, files)
+	}
+}
@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/ingredients"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultPantryMatchLimit = 20
+
+func GetUserPantry() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var mongoUser models.MongoUser
+		defer cancel()
+
+		err := usersCollection.FindOne(ctx, bson.M{"_id": c.Param("id")}).Decode(&mongoUser)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		pantry := models.Pantry{Ingredients: mongoUser.Pantry}
+		if pantry.Ingredients == nil {
+			pantry.Ingredients = []string{}
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched pantry for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": pantry}})
+	}
+}
+
+func UpdateUserPantry() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var pantry models.Pantry
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&pantry); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		cleaned := []string{}
+		for _, ingredient := range pantry.Ingredients {
+			if ingredient = strings.TrimSpace(ingredient); ingredient != "" {
+				cleaned = append(cleaned, ingredient)
+			}
+		}
+		pantry.Ingredients = cleaned
+
+		log.Println("Saving pantry of ", len(cleaned), " ingredients for user with ID ", c.Param("id"))
+		_, err := usersCollection.UpdateOne(ctx, bson.M{"_id": c.Param("id")}, bson.M{"$set": bson.M{"pantry": pantry.Ingredients}}, options.Update().SetUpsert(true))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully updated pantry for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": pantry}})
+	}
+}
+
+// GetPantryMatches ranks recipes by how many of their ingredients the user
+// already has, listing what's missing for each. ?limit= caps the number of
+// matches and ?maxMissing= drops recipes missing more than that many items.
+func GetPantryMatches() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireSelf(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var mongoUser models.MongoUser
+		defer cancel()
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPantryMatchLimit)))
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "limit must be a positive number"}})
+			return
+		}
+		maxMissing, err := strconv.Atoi(c.DefaultQuery("maxMissing", "-1"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "maxMissing must be a number"}})
+			return
+		}
+
+		err = usersCollection.FindOne(ctx, bson.M{"_id": c.Param("id")}).Decode(&mongoUser)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if len(mongoUser.Pantry) == 0 {
+			c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Pantry is empty, add ingredients to find matching recipes", Data: map[string]interface{}{"data": []models.PantryMatch{}}})
+			return
+		}
+
+		var pantry []string
+		var candidatePatterns []interface{}
+		for _, item := range mongoUser.Pantry {
+			normalized := ingredients.Canonical(item)
+			if normalized == "" {
+				continue
+			}
+			pantry = append(pantry, normalized)
+			// Only recipes mentioning something in the pantry can match at
+			// all, let Mongo narrow them down. Catalog entries go by any of
+			// their names, others by their last word.
+			if ingredients.LookupCatalog(item) != nil {
+				candidatePatterns = append(candidatePatterns, ingredientPattern(item))
+				continue
+			}
+			words := strings.Fields(normalized)
+			candidatePatterns = append(candidatePatterns, primitive.Regex{Pattern: `\b` + regexp.QuoteMeta(words[len(words)-1]), Options: "i"})
+		}
+
+		conditions := []bson.M{{"ingredients": bson.M{"$in": candidatePatterns}}, discoverableFilter(&keycloakUser)}
+		if mongoUser.Preferences != nil && mongoUser.Preferences.ApplyToListings && c.Query("applyPreferences") != "false" {
+			conditions = append(conditions, preferenceConditions(mongoUser.Preferences)...)
+		}
+		results, err := recipeCollection.Find(ctx, andFilter(conditions))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		//read from mongo optimally
+		defer results.Close(ctx)
+		matches := []models.PantryMatch{}
+		for results.Next(ctx) {
+			var singleRecipe models.Recipe
+			if err = results.Decode(&singleRecipe); err != nil {
+				c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+				return
+			}
+			match := matchPantry(singleRecipe, pantry)
+			if match.CoveredIngredients == 0 || (maxMissing >= 0 && len(match.MissingIngredients) > maxMissing) {
+				continue
+			}
+			matches = append(matches, match)
+		}
+
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].CoveredIngredients != matches[j].CoveredIngredients {
+				return matches[i].CoveredIngredients > matches[j].CoveredIngredients
+			}
+			return matches[i].Coverage > matches[j].Coverage
+		})
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully matched recipes against pantry for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": matches}})
+	}
+}
+
+// matchPantry works out which of the recipe's ingredients are covered by the
+// normalized pantry. Staples like salt and water are assumed to be on hand
+// and don't count either way.
+func matchPantry(recipe models.Recipe, pantry []string) models.PantryMatch {
+	match := models.PantryMatch{Recipe: recipe, MissingIngredients: []string{}}
+	for _, line := range recipe.Ingredients {
+		need := ingredients.Canonical(line)
+		if need == "" || ingredients.IsStaple(need) {
+			continue
+		}
+		match.TotalIngredients++
+		covered := false
+		for _, have := range pantry {
+			if ingredients.Covers(have, need) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			match.CoveredIngredients++
+		} else {
+			match.MissingIngredients = append(match.MissingIngredients, line)
+		}
+	}
+	if match.TotalIngredients > 0 {
+		match.Coverage = math.Round(float64(match.CoveredIngredients)/float64(match.TotalIngredients)*100) / 100
+	}
+	return match
+}
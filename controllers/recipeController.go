@@ -9,12 +9,12 @@ import (
 	"time"
 
 	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/dietary"
 	"github.com/hopk8412/table-recipes-api/models"
 	"github.com/hopk8412/table-recipes-api/responses"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -27,7 +27,21 @@ func GetAllRecipes() gin.HandlerFunc {
 		var recipes []models.Recipe
 		defer cancel()
 
-		results, err := recipeCollection.Find(ctx, bson.M{})
+		dietaryTags, excludeAllergens := queryList(c, "dietaryTags"), queryList(c, "excludeAllergens")
+		if err := validateDietary(dietaryTags, excludeAllergens); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		conditions, err := preferenceFilter(ctx, c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		conditions = append(conditions, dietaryFilter(dietaryTags, excludeAllergens)...)
+		conditions = append(conditions, discoverableFilter(optionalUser(c)))
+
+		results, err := recipeCollection.Find(ctx, andFilter(conditions))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
 			return
@@ -60,6 +74,12 @@ func GetRecipeById() gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
 			return
 		} 
+		if !canView(recipe, optionalUser(c)) {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no recipe with ID " + c.Param("id")}})
+			return
+		}
+		// Recipes saved before timers were detected still get them
+		annotateSteps(recipe.Instructions)
 		
 		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched recipe with ID " + c.Param("id"), Data: map[string]interface{}{"data": recipe}})
 		
@@ -68,46 +88,23 @@ func GetRecipeById() gin.HandlerFunc {
 
 func UpdateRecipeById() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
 		recipeId := c.Param("id")
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		var recipe models.Recipe
 		defer cancel()
 
-		result := recipeCollection.FindOne(ctx, bson.M{"_id": c.Param("id")})
-
-		err := result.Decode(&recipe) 
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
-			return
-		}
-
 		var requestBody models.Recipe
 		c.Bind(&requestBody)
 
-		updates := bson.M{
-			"$set": bson.M{
-				"_id": recipeId,
-				"title": requestBody.Title,
-				"ingredients": requestBody.Ingredients,
-				"instructions": requestBody.Instructions,
-				"authorId": recipe.AuthorId,
-				"imageLinks": requestBody.ImageLinks,
-			},
-		}
-		_, err = recipeCollection.UpdateByID(ctx, recipeId, updates)
+		updatedRecipeData, err := updateRecipe(ctx, keycloakUser, recipeId, requestBody)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			respondWithError(c, err)
 			return
 		}
 
-		updatedRecipeData := models.Recipe{
-			Id: recipeId,
-			Title: requestBody.Title,
-			Ingredients: requestBody.Ingredients,
-			Instructions: requestBody.Instructions,
-			AuthorId: recipe.AuthorId,
-			ImageLinks: requestBody.ImageLinks,
-		}
 		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully updated recipe with ID " + recipeId, Data: map[string]interface{}{"data": updatedRecipeData}})
 	}
 }
@@ -163,6 +160,10 @@ func GetRecipesByAuthorId() gin.HandlerFunc {
 
 func PostRecipe() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		var recipe models.Recipe
 		defer cancel()
@@ -173,34 +174,29 @@ func PostRecipe() gin.HandlerFunc {
 			return
 		}
 
-		//TODO: validate required fields
-
-		newRecipe := models.Recipe{
-			Id: primitive.NewObjectID().Hex(),
-			Title: recipe.Title,
-			Ingredients: recipe.Ingredients,
-			Instructions: recipe.Instructions,
-			AuthorId: recipe.AuthorId,
-			ImageLinks: recipe.ImageLinks,
-		}
-		result, err := recipeCollection.InsertOne(ctx, newRecipe)
+		newRecipe, err := createRecipe(ctx, keycloakUser, recipe)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			respondWithError(c, err)
 			return
 		}
+		result := &mongo.InsertOneResult{InsertedID: newRecipe.Id}
 		c.JSON(http.StatusCreated, responses.RecipeResponse{Status: http.StatusCreated, Message: "Successfully created recipe!", Data: map[string]interface{}{"data": result}})
 	}
 }
 
 func DeleteRecipeById() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		recipeId := c.Param("id")
 		defer cancel()
 
-		result, err := recipeCollection.DeleteOne(ctx, bson.M{"_id": recipeId})
+		result, err := deleteRecipe(ctx, keycloakUser, recipeId)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			respondWithError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully deleted recipe with ID " + recipeId, Data: map[string]interface{}{"data": result}})
@@ -220,7 +216,18 @@ func SearchForRecipes() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
 			return
 		}
-		results, err := recipeCollection.Find(ctx, bson.D{{Key: "title", Value: primitive.Regex{Pattern: searchQuery.SearchTerm, Options: "i"}}})
+		if err := validateSearchQuery(searchQuery); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		conditions, err := preferenceFilter(ctx, c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		conditions = append(conditions, discoverableFilter(optionalUser(c)))
+		filter := andFilter(append(conditions, searchConditions(searchQuery)...))
+		results, err := recipeCollection.Find(ctx, filter)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
 			return
@@ -234,7 +241,12 @@ func SearchForRecipes() gin.HandlerFunc {
 			}
 			recipes = append(recipes, singleRecipe)
 		}
-		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched all recipes with title containing '" + searchQuery.SearchTerm + "'!", Data: map[string]interface{}{"data": recipes}})
+		facets, err := searchFacets(ctx, conditions, searchQuery)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched all recipes with title containing '" + searchQuery.SearchTerm + "'!", Data: map[string]interface{}{"data": recipes, "facets": facets}})
 	}
 }
 
@@ -271,7 +283,6 @@ func AddOrRemoveRecipeToUserFavorites() gin.HandlerFunc {
 			log.Println("Token was validated for user with ID ", keycloakUser.Sub, " - persisting user to mongo if not already there...")
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			var userRecipeOperation models.UserRecipeOperation
-			var mongoUser models.MongoUser
 			defer cancel()
 
 			//validate request body
@@ -280,52 +291,17 @@ func AddOrRemoveRecipeToUserFavorites() gin.HandlerFunc {
 				return
 			}
 
-			err := usersCollection.FindOne(ctx, bson.M{"_id": c.Param("id")}).Decode(&mongoUser)
+			log.Println("Adding/removing recipe with ID ", userRecipeOperation.RecipeId, " for user...")
+			result, err := setFavorite(ctx, keycloakUser, userRecipeOperation.RecipeId, userRecipeOperation.IsAddingFavorite)
 			if err != nil {
-				if err == mongo.ErrNoDocuments {
-					log.Println("User does not exist, insert now and add provided recipe to their favorites...")
-					newMongoUser := models.MongoUser{
-						Id: c.Param("id"),
-						FavoriteRecipes: []string{userRecipeOperation.RecipeId},
-					}
-					// Insert new user record
-					result, err := usersCollection.InsertOne(ctx, newMongoUser)
-					if err != nil {
-						c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
-						return
-					}
-					c.JSON(http.StatusCreated, responses.RecipeResponse{Status: http.StatusCreated, Message: "Successfully created recipe!", Data: map[string]interface{}{"data": result}})
-					return
-				} else {
-					c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
-					return
-				}
+				respondWithError(c, err)
+				return
 			}
-			// At this point, we know the mongo user exists, perform requested operation and save change
-			log.Println("User already exists, adding/removing recipe now...")
-			if userRecipeOperation.IsAddingFavorite {
-				mongoUser.FavoriteRecipes = append(mongoUser.FavoriteRecipes, userRecipeOperation.RecipeId)
-				update := bson.M{"favoriteRecipes": mongoUser.FavoriteRecipes}
-				result, err := usersCollection.UpdateOne(ctx, bson.M{"_id": c.Param("id")}, bson.M{"$set": update})
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
-					return
-				}
+			if result.UpsertedCount > 0 {
+				c.JSON(http.StatusCreated, responses.RecipeResponse{Status: http.StatusCreated, Message: "Successfully created recipe!", Data: map[string]interface{}{"data": result}})
+			} else if userRecipeOperation.IsAddingFavorite {
 				c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully added recipe to user favorites!", Data: map[string]interface{}{"data": result}})
 			} else {
-				log.Println("Removing recipe with ID ", userRecipeOperation.RecipeId, " from users favorites...")
-				for i, v := range mongoUser.FavoriteRecipes {
-					if v == userRecipeOperation.RecipeId {
-						mongoUser.FavoriteRecipes = append(mongoUser.FavoriteRecipes[:i], mongoUser.FavoriteRecipes[i+1:]...)
-						break
-					}
-				}
-				update := bson.M{"favoriteRecipes": mongoUser.FavoriteRecipes}
-				result, err := usersCollection.UpdateOne(ctx, bson.M{"_id": c.Param("id")}, bson.M{"$set": update})
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
-					return
-				}
 				c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully removed recipe from user favorites!", Data: map[string]interface{}{"data": result}})
 			}
 		}
@@ -374,7 +350,8 @@ func GetUserFavoriteRecipes() gin.HandlerFunc {
 				c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error validating user", Data: map[string]interface{}{"data": err.Error()}})
 			}
 			// We now have the mongo user, so use their favorite recipe ID slice to query recipe collection...
-			filter := bson.M{"_id": bson.M{"$in": mongoUser.FavoriteRecipes}}
+			// Favorites the author has since made private drop out
+			filter := andFilter([]bson.M{{"_id": bson.M{"$in": mongoUser.FavoriteRecipes}}, accessibleFilter(&keycloakUser)})
 			var recipes []models.Recipe
 			defer cancel()
 
@@ -396,4 +373,11 @@ func GetUserFavoriteRecipes() gin.HandlerFunc {
 			c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched all recipes!", Data: map[string]interface{}{"data": recipes}})
 		}
 	}
+}
+
+func GetDietaryDictionary() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dictionary := map[string]interface{}{"dietaryTags": dietary.Tags(), "allergens": dietary.Allergens()}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched dietary tags and allergens!", Data: map[string]interface{}{"data": dictionary}})
+	}
 }
\ No newline at end of file
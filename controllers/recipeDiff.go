@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"golang.org/x/exp/slices"
+)
+
+// diffRecipes lists what changed going from base to recipe.
+func diffRecipes(base models.Recipe, recipe models.Recipe) models.RecipeDiff {
+	diff := models.RecipeDiff{
+		RecipeId:     recipe.Id,
+		BaseRecipeId: base.Id,
+		Fields:       map[string]models.FieldChange{},
+		Ingredients:  diffLines(base.Ingredients, recipe.Ingredients),
+		Instructions: diffLines(stepTexts(base.Instructions), stepTexts(recipe.Instructions)),
+	}
+	compare := func(name string, from interface{}, to interface{}, equal bool) {
+		if !equal {
+			diff.Fields[name] = models.FieldChange{From: from, To: to}
+		}
+	}
+	compare("title", base.Title, recipe.Title, base.Title == recipe.Title)
+	compare("imageLinks", base.ImageLinks, recipe.ImageLinks, base.ImageLinks == recipe.ImageLinks)
+	compare("servings", base.Servings, recipe.Servings, base.Servings == recipe.Servings)
+	compare("yield", base.Yield, recipe.Yield, base.Yield == recipe.Yield || (base.Yield != nil && recipe.Yield != nil && *base.Yield == *recipe.Yield))
+	compare("cuisine", base.Cuisine, recipe.Cuisine, base.Cuisine == recipe.Cuisine)
+	compare("course", base.Course, recipe.Course, base.Course == recipe.Course)
+	compare("difficulty", base.Difficulty, recipe.Difficulty, base.Difficulty == recipe.Difficulty)
+	compare("prepTime", base.PrepTime, recipe.PrepTime, base.PrepTime == recipe.PrepTime)
+	compare("cookTime", base.CookTime, recipe.CookTime, base.CookTime == recipe.CookTime)
+	compare("totalTime", base.TotalTime, recipe.TotalTime, base.TotalTime == recipe.TotalTime)
+	compare("dietaryTags", base.DietaryTags, recipe.DietaryTags, slices.Equal(base.DietaryTags, recipe.DietaryTags))
+	return diff
+}
+
+func stepTexts(steps []models.Step) []string {
+	texts := make([]string, len(steps))
+	for i, step := range steps {
+		texts[i] = step.Text
+	}
+	return texts
+}
+
+// diffLines is a line diff from the longest common subsequence of the two
+// lists. Recipes are short, so the quadratic table is fine.
+func diffLines(from []string, to []string) []models.DiffLine {
+	common := make([][]int, len(from)+1)
+	for i := range common {
+		common[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	lines := []models.DiffLine{}
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			lines = append(lines, models.DiffLine{Op: models.DiffUnchanged, Text: from[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, models.DiffLine{Op: models.DiffRemoved, Text: from[i]})
+			i++
+		default:
+			lines = append(lines, models.DiffLine{Op: models.DiffAdded, Text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		lines = append(lines, models.DiffLine{Op: models.DiffRemoved, Text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, models.DiffLine{Op: models.DiffAdded, Text: to[j]})
+	}
+	return lines
+}
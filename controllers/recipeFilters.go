@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hopk8412/table-recipes-api/dietary"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// dietaryFilter matches recipes carrying every one of tags, either supplied
+// by the author or detected from the ingredients, and none of the allergens.
+// Recipes saved before detection have no allergens yet, they're left out
+// when excluding allergens rather than assumed safe.
+func dietaryFilter(tags []string, excludeAllergens []string) []bson.M {
+	var conditions []bson.M
+	for _, tag := range tags {
+		conditions = append(conditions, bson.M{"$or": []bson.M{
+			{"dietaryTags": tag},
+			{"detectedDietaryTags": tag},
+		}})
+	}
+	if len(excludeAllergens) > 0 {
+		conditions = append(conditions, bson.M{"allergens": bson.M{"$exists": true, "$nin": excludeAllergens}})
+	}
+	return conditions
+}
+
+// andFilter combines conditions into a single filter document.
+func andFilter(conditions []bson.M) bson.M {
+	switch len(conditions) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return conditions[0]
+	}
+	return bson.M{"$and": conditions}
+}
+
+func validateDietary(tags []string, allergens []string) error {
+	for _, tag := range tags {
+		if !dietary.IsTag(tag) {
+			return fmt.Errorf("unknown dietary tag '%s'", tag)
+		}
+	}
+	for _, allergen := range allergens {
+		if !dietary.IsAllergen(allergen) {
+			return fmt.Errorf("unknown allergen '%s'", allergen)
+		}
+	}
+	return nil
+}
+
+// queryList reads a comma separated query parameter, e.g.
+// ?dietaryTags=vegan,gluten-free
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, value := range strings.Split(c.Query(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/events"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ForkRecipe copies a recipe into the caller's own recipes so they can change
+// it without touching the original. The body may set a new title.
+func ForkRecipe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var requestBody struct {
+			Title string `json:"title"`
+		}
+		defer cancel()
+
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&requestBody); err != nil {
+				c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+				return
+			}
+		}
+		parent, ok := findViewableRecipe(ctx, c, c.Param("id"), &keycloakUser)
+		if !ok {
+			return
+		}
+
+		fork := parent
+		fork.Id = primitive.NewObjectID().Hex()
+		fork.AuthorId = keycloakUser.Sub
+		fork.ParentRecipeId = parent.Id
+		fork.ParentAuthorId = parent.AuthorId
+		fork.AverageRating, fork.RatingCount = 0, 0
+		// The parent's collaborators were invited to the parent, not the fork
+		fork.Collaborators = nil
+		// Forks start out as private drafts like any other new recipe
+		fork.Visibility = models.VisibilityPrivate
+		fork.Status = models.StatusDraft
+		fork.PublishedAt, fork.ArchivedAt = nil, nil
+		if requestBody.Title != "" {
+			fork.Title = requestBody.Title
+		}
+		if err := prepareRecipe(&fork, &parent); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		log.Println("Forking recipe with ID ", parent.Id, " for user with ID ", keycloakUser.Sub)
+		if _, err := recipeCollection.InsertOne(ctx, fork); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		recordDisplayName(ctx, keycloakUser)
+		notify(ctx, models.Notification{
+			Type:        models.NotificationRecipeForked,
+			RecipientId: parent.AuthorId,
+			ActorId:     keycloakUser.Sub,
+			ActorName:   keycloakUser.PreferredUsername,
+			RecipeId:    parent.Id,
+			RecipeTitle: parent.Title,
+			ForkId:      fork.Id,
+		})
+		publishRecipeWebhook(ctx, models.WebhookRecipeCreated, map[string]interface{}{"recipe": fork})
+		publishRecipeChange(events.RecipeCreated, fork.Id, &fork)
+		c.JSON(http.StatusCreated, responses.RecipeResponse{Status: http.StatusCreated, Message: "Successfully forked recipe with ID " + parent.Id, Data: map[string]interface{}{"data": fork}})
+	}
+}
+
+// GetRecipeLineage returns the chain of recipes a recipe was forked from and
+// the recipes forked from it.
+func GetRecipeLineage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		viewer := optionalUser(c)
+		defer cancel()
+
+		pipeline := bson.A{
+			bson.M{"$match": andFilter([]bson.M{{"_id": c.Param("id")}, accessibleFilter(viewer)})},
+			bson.M{"$graphLookup": bson.M{
+				"from":             recipeCollection.Name(),
+				"startWith":        "$parentRecipeId",
+				"connectFromField": "parentRecipeId",
+				"connectToField":   "_id",
+				"as":               "ancestors",
+				"depthField":       "depth",
+			}},
+			bson.M{"$graphLookup": bson.M{
+				"from":             recipeCollection.Name(),
+				"startWith":        "$_id",
+				"connectFromField": "_id",
+				"connectToField":   "parentRecipeId",
+				"as":               "descendants",
+				"depthField":       "depth",
+			}},
+		}
+		cursor, err := recipeCollection.Aggregate(ctx, pipeline)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		type related struct {
+			models.Recipe `bson:",inline"`
+			Depth         int `bson:"depth"`
+		}
+		var results []struct {
+			Ancestors   []related `bson:"ancestors"`
+			Descendants []related `bson:"descendants"`
+		}
+		if err = cursor.All(ctx, &results); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if len(results) == 0 {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no recipe with ID " + c.Param("id")}})
+			return
+		}
+
+		// $graphLookup doesn't order its results, depth 0 is the parent
+		chain := make([]models.Recipe, len(results[0].Ancestors))
+		for _, ancestor := range results[0].Ancestors {
+			chain[len(chain)-1-ancestor.Depth] = ancestor.Recipe
+		}
+		lineage := models.RecipeLineage{
+			Ancestors: []models.Recipe{},
+			Forks:     []models.Recipe{},
+		}
+		// Private recipes in the chain are left out, ParentAuthorId on the
+		// next one down keeps the attribution. Unlisted forks are left out
+		// the same way they are from any other listing.
+		for _, ancestor := range chain {
+			if canView(ancestor, viewer) {
+				lineage.Ancestors = append(lineage.Ancestors, ancestor)
+			}
+		}
+		// Only descendants the viewer could open count, so the count doesn't
+		// give away private forks and drafts
+		for _, descendant := range results[0].Descendants {
+			if canView(descendant.Recipe, viewer) {
+				lineage.DescendantCount++
+			}
+			if descendant.Depth == 0 && isDiscoverable(descendant.Recipe, viewer) {
+				lineage.Forks = append(lineage.Forks, descendant.Recipe)
+			}
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched lineage of recipe with ID " + c.Param("id"), Data: map[string]interface{}{"data": lineage}})
+	}
+}
+
+// GetRecipeDiff compares a recipe with the one it was forked from, or with
+// any other recipe given as ?against=.
+func GetRecipeDiff() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		viewer := optionalUser(c)
+		recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), viewer)
+		if !ok {
+			return
+		}
+		baseId := c.DefaultQuery("against", recipe.ParentRecipeId)
+		if baseId == "" {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "recipe with ID " + recipe.Id + " isn't a fork, pass ?against= to compare it with another recipe"}})
+			return
+		}
+		base, ok := findViewableRecipe(ctx, c, baseId, viewer)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully compared recipe with ID " + recipe.Id + " against recipe with ID " + base.Id, Data: map[string]interface{}{"data": diffRecipes(base, recipe)}})
+	}
+}
+
+// findViewableRecipe is viewableRecipe for handlers, writing a 404 when
+// the recipe doesn't exist or is someone else's private recipe.
+func findViewableRecipe(ctx context.Context, c *gin.Context, recipeId string, viewer *models.KeycloakUser) (models.Recipe, bool) {
+	recipe, err := viewableRecipe(ctx, recipeId, viewer)
+	if err != nil {
+		respondWithError(c, err)
+		return recipe, false
+	}
+	return recipe, true
+}
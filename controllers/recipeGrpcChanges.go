@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/events"
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recipeChangesFromEvents is set once recipe events from the bus are handed
+// to ListChanges streams. Until then the handlers hand out the changes they
+// make themselves, like publishRecipeWebhook.
+var recipeChangesFromEvents bool
+
+// recipeChangeBuffer is how many changes a ListChanges stream may fall
+// behind before it's dropped.
+const recipeChangeBuffer = 64
+
+// recipeChangeHub hands every recipe event to each open ListChanges stream.
+type recipeChangeHub struct {
+	mu        sync.Mutex
+	listeners map[chan events.Event]bool
+}
+
+var recipeChanges = &recipeChangeHub{listeners: map[chan events.Event]bool{}}
+
+// listen returns a channel receiving the events published from now on. It
+// is closed if the listener falls too far behind.
+func (h *recipeChangeHub) listen() chan events.Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	listener := make(chan events.Event, recipeChangeBuffer)
+	h.listeners[listener] = true
+	return listener
+}
+
+func (h *recipeChangeHub) stop(listener chan events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.listeners[listener] {
+		delete(h.listeners, listener)
+		close(listener)
+	}
+}
+
+// publish never blocks, so one slow stream can't hold up the bus.
+func (h *recipeChangeHub) publish(event events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for listener := range h.listeners {
+		select {
+		case listener <- event:
+		default:
+			delete(h.listeners, listener)
+			close(listener)
+		}
+	}
+}
+
+// publishRecipeChange hands a change made by the handlers to ListChanges
+// streams, unless the event bus is already taking care of them. recipe is
+// nil for deletions.
+func publishRecipeChange(eventType string, recipeId string, recipe *models.Recipe) {
+	if recipeChangesFromEvents {
+		return
+	}
+	event := events.Event{
+		Id:         primitive.NewObjectID().Hex(),
+		Type:       eventType,
+		EntityId:   recipeId,
+		OccurredAt: time.Now().UTC(),
+		Data:       map[string]interface{}{"recipeId": recipeId},
+	}
+	if recipe != nil {
+		event.Data["recipe"] = *recipe
+	}
+	recipeChanges.publish(event)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/hopk8412/table-recipes-api/events"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/recipespb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func recipeToProto(recipe models.Recipe) *recipespb.Recipe {
+	message := &recipespb.Recipe{
+		Id:                  recipe.Id,
+		Title:               recipe.Title,
+		Ingredients:         recipe.Ingredients,
+		Instructions:        stepsToProto(recipe.Instructions),
+		AuthorId:            recipe.AuthorId,
+		ImageLinks:          recipe.ImageLinks,
+		Servings:            int32(recipe.Servings),
+		DietaryTags:         recipe.DietaryTags,
+		DetectedDietaryTags: recipe.DetectedDietaryTags,
+		Allergens:           recipe.Allergens,
+		Cuisine:             recipe.Cuisine,
+		Course:              recipe.Course,
+		Difficulty:          recipe.Difficulty,
+		PrepTime:            recipe.PrepTime,
+		CookTime:            recipe.CookTime,
+		TotalTime:           recipe.TotalTime,
+		TotalTimeMinutes:    int32(recipe.TotalTimeMinutes),
+		AverageRating:       recipe.AverageRating,
+		RatingCount:         int32(recipe.RatingCount),
+		ParentRecipeId:      recipe.ParentRecipeId,
+		ParentAuthorId:      recipe.ParentAuthorId,
+		Visibility:          recipe.Visibility,
+		Status:              recipe.Status,
+	}
+	if recipe.Yield != nil {
+		message.Yield = &recipespb.Yield{Quantity: recipe.Yield.Quantity, Unit: recipe.Yield.Unit}
+	}
+	if recipe.PublishedAt != nil {
+		message.PublishedAt = timestamppb.New(*recipe.PublishedAt)
+	}
+	if recipe.ArchivedAt != nil {
+		message.ArchivedAt = timestamppb.New(*recipe.ArchivedAt)
+	}
+	return message
+}
+
+func stepsToProto(steps []models.Step) []*recipespb.Step {
+	var messages []*recipespb.Step
+	for _, step := range steps {
+		message := &recipespb.Step{Section: step.Section, Text: step.Text, Timer: step.Timer, ImageLink: step.ImageLink}
+		for _, ingredient := range step.Ingredients {
+			message.Ingredients = append(message.Ingredients, int32(ingredient))
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// recipeFromProto reads the fields of a recipe its author controls, like
+// binding a models.Recipe from a request body does.
+func recipeFromProto(input *recipespb.RecipeInput) models.Recipe {
+	recipe := models.Recipe{
+		Title:       input.GetTitle(),
+		Ingredients: input.GetIngredients(),
+		ImageLinks:  input.GetImageLinks(),
+		Servings:    int(input.GetServings()),
+		DietaryTags: input.GetDietaryTags(),
+		Cuisine:     input.GetCuisine(),
+		Course:      input.GetCourse(),
+		Difficulty:  input.GetDifficulty(),
+		PrepTime:    input.GetPrepTime(),
+		CookTime:    input.GetCookTime(),
+		TotalTime:   input.GetTotalTime(),
+		Visibility:  input.GetVisibility(),
+	}
+	for _, message := range input.GetInstructions() {
+		step := models.Step{Section: message.GetSection(), Text: message.GetText(), Timer: message.GetTimer(), ImageLink: message.GetImageLink()}
+		for _, ingredient := range message.GetIngredients() {
+			step.Ingredients = append(step.Ingredients, int(ingredient))
+		}
+		recipe.Instructions = append(recipe.Instructions, step)
+	}
+	if yield := input.GetYield(); yield != nil {
+		recipe.Yield = &models.Yield{Quantity: yield.GetQuantity(), Unit: yield.GetUnit()}
+	}
+	return recipe
+}
+
+// recipeChangeToProto turns a recipe event into a change for the viewer, nil
+// if they can't see the recipe.
+func recipeChangeToProto(event events.Event, viewer *models.KeycloakUser) (*recipespb.RecipeChange, error) {
+	change := &recipespb.RecipeChange{
+		Id:            event.Id,
+		Type:          event.Type,
+		RecipeId:      event.EntityId,
+		OccurredAt:    timestamppb.New(event.OccurredAt),
+		ChangedFields: event.ChangedFields,
+	}
+	if event.Type == events.RecipeDeleted {
+		return change, nil
+	}
+	// The recipe is a models.Recipe on the local bus and a map once it went
+	// through NATS, JSON reads both
+	raw, err := json.Marshal(event.Data["recipe"])
+	if err != nil {
+		return nil, err
+	}
+	var recipe models.Recipe
+	if err = json.Unmarshal(raw, &recipe); err != nil {
+		return nil, err
+	}
+	if recipe.Id == "" || !canView(recipe, viewer) {
+		// Deleted before the change stream looked it up, the deletion
+		// follows, or not the viewer's to see
+		return nil, nil
+	}
+	change.Recipe = recipeToProto(recipe)
+	return change, nil
+}
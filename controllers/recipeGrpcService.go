@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/recipespb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const defaultGrpcPageSize = 20
+const maxGrpcPageSize = 100
+
+// recipeGrpcService serves recipespb.RecipeService with the same logic as
+// the REST handlers.
+type recipeGrpcService struct {
+	recipespb.UnimplementedRecipeServiceServer
+}
+
+// NewGrpcServer returns a gRPC server with RecipeService registered,
+// authenticating calls with the bearer tokens the REST API takes.
+func NewGrpcServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcAuthUnaryInterceptor),
+		grpc.StreamInterceptor(grpcAuthStreamInterceptor),
+	)
+	recipespb.RegisterRecipeServiceServer(server, &recipeGrpcService{})
+	return server
+}
+
+type grpcViewerKey struct{}
+
+// grpcAuthenticate checks the token in the call's "authorization" metadata
+// with Keycloak and puts the user it belongs to in the context. Calls
+// without a token are anonymous, calls with a bad one are rejected.
+func grpcAuthenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return ctx, nil
+	}
+	keycloakUser, err := keycloakUserInfo(values[0])
+	if err != nil {
+		log.Println("Error validating gRPC token: ", err)
+		return ctx, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+	return context.WithValue(ctx, grpcViewerKey{}, &keycloakUser), nil
+}
+
+func grpcAuthUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := grpcAuthenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func grpcAuthStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := grpcAuthenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &grpcAuthenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// grpcAuthenticatedStream is a stream whose context carries the caller.
+type grpcAuthenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *grpcAuthenticatedStream) Context() context.Context {
+	return stream.ctx
+}
+
+// grpcViewer is the caller, nil when anonymous.
+func grpcViewer(ctx context.Context) *models.KeycloakUser {
+	viewer, _ := ctx.Value(grpcViewerKey{}).(*models.KeycloakUser)
+	return viewer
+}
+
+// grpcUser is the caller of a call that needs one.
+func grpcUser(ctx context.Context) (models.KeycloakUser, error) {
+	viewer := grpcViewer(ctx)
+	if viewer == nil {
+		return models.KeycloakUser{}, status.Error(codes.Unauthenticated, errMissingToken.Error())
+	}
+	return *viewer, nil
+}
+
+// grpcError turns errors from the shared business logic into the status the
+// REST API's status code stands for.
+func grpcError(err error) error {
+	var requestErr *serviceError
+	if !errors.As(err, &requestErr) {
+		log.Println("Error handling gRPC call: ", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Internal
+	switch requestErr.status {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, requestErr.message)
+}
+
+func (service *recipeGrpcService) Get(ctx context.Context, req *recipespb.GetRecipeRequest) (*recipespb.Recipe, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe, err := viewableRecipe(ctx, req.GetId(), grpcViewer(ctx))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return recipeToProto(recipe), nil
+}
+
+func (service *recipeGrpcService) List(ctx context.Context, req *recipespb.ListRecipesRequest) (*recipespb.ListRecipesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := validateDietary(req.GetDietaryTags(), req.GetExcludeAllergens()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return listRecipePage(ctx, req.GetPageSize(), req.GetPageToken(), !req.GetIgnorePreferences(), dietaryFilter(req.GetDietaryTags(), req.GetExcludeAllergens()))
+}
+
+func (service *recipeGrpcService) Search(ctx context.Context, req *recipespb.SearchRecipesRequest) (*recipespb.ListRecipesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	searchQuery := models.SearchQuery{
+		SearchTerm:         req.GetSearchTerm(),
+		IncludeIngredients: req.GetIncludeIngredients(),
+		ExcludeIngredients: req.GetExcludeIngredients(),
+		MaxTotalTime:       int(req.GetMaxTotalTime()),
+		Cuisine:            req.GetCuisine(),
+		Course:             req.GetCourse(),
+		Difficulty:         req.GetDifficulty(),
+		DietaryTags:        req.GetDietaryTags(),
+		ExcludeAllergens:   req.GetExcludeAllergens(),
+		MinRating:          req.GetMinRating(),
+		AuthorId:           req.GetAuthorId(),
+	}
+	if err := validateSearchQuery(searchQuery); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return listRecipePage(ctx, req.GetPageSize(), req.GetPageToken(), !req.GetIgnorePreferences(), searchConditions(searchQuery))
+}
+
+// listRecipePage returns a page of the listable recipes matching conditions,
+// newest first. The page token is the ID of the last recipe on the previous
+// page, which unlike an offset doesn't shift as recipes are added.
+func listRecipePage(ctx context.Context, pageSize int32, pageToken string, applyPreferences bool, conditions []bson.M) (*recipespb.ListRecipesResponse, error) {
+	if pageSize < 0 || pageSize > maxGrpcPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 1 and %d", maxGrpcPageSize)
+	}
+	if pageSize == 0 {
+		pageSize = defaultGrpcPageSize
+	}
+	if pageToken != "" {
+		conditions = append(conditions, bson.M{"_id": bson.M{"$lt": pageToken}})
+	}
+	filter, err := listingFilter(ctx, grpcViewer(ctx), applyPreferences, conditions)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	// One extra recipe tells whether there's another page
+	recipes, err := findRecipes(ctx, filter, options.Find().SetSort(bson.M{"_id": -1}).SetLimit(int64(pageSize)+1))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	response := &recipespb.ListRecipesResponse{}
+	if len(recipes) > int(pageSize) {
+		recipes = recipes[:pageSize]
+		response.NextPageToken = recipes[len(recipes)-1].Id
+	}
+	for _, recipe := range recipes {
+		response.Recipes = append(response.Recipes, recipeToProto(recipe))
+	}
+	return response, nil
+}
+
+func (service *recipeGrpcService) Create(ctx context.Context, req *recipespb.CreateRecipeRequest) (*recipespb.Recipe, error) {
+	keycloakUser, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe := recipeFromProto(req.GetRecipe())
+	log.Println("Creating recipe over gRPC for user with ID ", keycloakUser.Sub, "...")
+	newRecipe, err := createRecipe(ctx, keycloakUser, recipe)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return recipeToProto(newRecipe), nil
+}
+
+func (service *recipeGrpcService) Update(ctx context.Context, req *recipespb.UpdateRecipeRequest) (*recipespb.Recipe, error) {
+	keycloakUser, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe, err := updateRecipe(ctx, keycloakUser, req.GetId(), recipeFromProto(req.GetRecipe()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return recipeToProto(recipe), nil
+}
+
+func (service *recipeGrpcService) Delete(ctx context.Context, req *recipespb.DeleteRecipeRequest) (*recipespb.DeleteRecipeResponse, error) {
+	keycloakUser, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	result, err := deleteRecipe(ctx, keycloakUser, req.GetId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &recipespb.DeleteRecipeResponse{Deleted: result.DeletedCount > 0}, nil
+}
+
+func (service *recipeGrpcService) ListChanges(req *recipespb.ListChangesRequest, stream recipespb.RecipeService_ListChangesServer) error {
+	viewer := grpcViewer(stream.Context())
+	changes := recipeChanges.listen()
+	defer recipeChanges.stop(changes)
+	for {
+		select {
+		case event, ok := <-changes:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "stream fell behind on recipe changes, call ListChanges again")
+			}
+			change, err := recipeChangeToProto(event, viewer)
+			if err != nil {
+				log.Println("Error decoding recipe change ", event.Id, ": ", err)
+				continue
+			}
+			if change == nil {
+				continue
+			}
+			if err = stream.Send(change); err != nil {
+				return err
+			}
+		case <-liveStreams.closed:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// recipeIndexedFields are the recipe fields listings and search filter on.
+var recipeIndexedFields = []string{
+	"authorId",
+	"cuisine",
+	"course",
+	"difficulty",
+	"totalTimeMinutes",
+	"averageRating",
+	"dietaryTags",
+	"detectedDietaryTags",
+	"allergens",
+	"parentRecipeId",
+	"visibility",
+	"status",
+	"collaborators.userId",
+}
+
+// EnsureRecipeIndexes creates the indexes used for filtering recipes.
+// Creating an index that already exists is a no-op, so it's safe to call on
+// every startup.
+func EnsureRecipeIndexes(ctx context.Context) error {
+	indexes := make([]mongo.IndexModel, 0, len(recipeIndexedFields))
+	for _, field := range recipeIndexedFields {
+		indexes = append(indexes, mongo.IndexModel{Keys: bson.D{{Key: field, Value: 1}}})
+	}
+	_, err := recipeCollection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
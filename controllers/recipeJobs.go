@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/dietary"
+	"github.com/hopk8412/table-recipes-api/events"
+	"github.com/hopk8412/table-recipes-api/jobs"
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	JobTypeRecipeExport          = "recipes.export"
+	JobTypeRecipeImport          = "recipes.import"
+	JobTypeRecipeDietaryBackfill = "recipes.dietaryBackfill"
+)
+
+// exportBatchSize is how many exported recipes are written at once.
+const exportBatchSize = 100
+
+var recipeExportCollection *mongo.Collection = configs.GetCollection(configs.DB, "recipeExports")
+
+// recipeExport is one recipe of an export job's result. They're kept out of
+// the job document, which a large export would outgrow.
+type recipeExport struct {
+	Id     string        `bson:"_id"`
+	JobId  string        `bson:"jobId"`
+	Index  int           `bson:"index"`
+	Recipe models.Recipe `bson:"recipe"`
+}
+
+// EnsureRecipeJobIndexes creates the index exports are read back by.
+func EnsureRecipeJobIndexes(ctx context.Context) error {
+	_, err := recipeExportCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "jobId", Value: 1}, {Key: "index", Value: 1}}})
+	return err
+}
+
+// undetectedFilter matches recipes saved before allergens and dietary tags
+// were detected.
+var undetectedFilter = bson.M{"allergens": bson.M{"$exists": false}}
+
+// RegisterRecipeJobs makes the long-running recipe operations available to
+// the job queue.
+func RegisterRecipeJobs() {
+	jobs.Register(JobTypeRecipeExport, exportRecipesJob)
+	jobs.Register(JobTypeRecipeImport, importRecipesJob)
+	jobs.RegisterInternal(JobTypeRecipeDietaryBackfill, backfillDietaryJob)
+}
+
+// QueueDietaryBackfill queues detecting allergens and dietary tags for the
+// recipes saved before detection, if there are any. Until then allergen
+// filters leave them out.
+func QueueDietaryBackfill(ctx context.Context) error {
+	count, err := recipeCollection.CountDocuments(ctx, undetectedFilter)
+	if err != nil || count == 0 {
+		return err
+	}
+	log.Println("Queueing allergen detection for ", count, " recipes saved before it")
+	_, err = jobs.Enqueue(ctx, JobTypeRecipeDietaryBackfill, "", nil)
+	return err
+}
+
+// backfillDietaryJob detects allergens and dietary tags for every recipe
+// that doesn't have them yet. Recipes are only updated once, so several
+// replicas queueing it at startup just find less to do.
+func backfillDietaryJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (map[string]interface{}, error) {
+	total, err := recipeCollection.CountDocuments(ctx, undetectedFilter)
+	if err != nil {
+		return nil, err
+	}
+	results, err := recipeCollection.Find(ctx, undetectedFilter)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+
+	updated := 0
+	for results.Next(ctx) {
+		var recipe models.Recipe
+		if err = results.Decode(&recipe); err != nil {
+			return nil, err
+		}
+		detectedTags, allergens := dietary.Detect(recipe.Ingredients)
+		_, err = recipeCollection.UpdateOne(ctx, bson.M{"_id": recipe.Id, "allergens": bson.M{"$exists": false}}, bson.M{"$set": bson.M{
+			"dietaryTags":         dietary.Consistent(recipe.DietaryTags, recipe.Ingredients),
+			"detectedDietaryTags": detectedTags,
+			"allergens":           allergens,
+		}})
+		if err != nil {
+			return nil, err
+		}
+		updated++
+		if total > 0 {
+			progress(updated * 100 / int(total))
+		}
+	}
+	if err = results.Err(); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"count": updated}, nil
+}
+
+// exportRecipesJob exports every recipe created by the job's owner. The
+// recipes go to recipeExportCollection, the result only counts them, and
+// exportedRecipes reads them back.
+func exportRecipesJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (map[string]interface{}, error) {
+	filter := bson.M{"authorId": job.OwnerId}
+	total, err := recipeCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	// Start over from what an earlier attempt left behind
+	if _, err = recipeExportCollection.DeleteMany(ctx, bson.M{"jobId": job.Id}); err != nil {
+		return nil, err
+	}
+
+	results, err := recipeCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+	count := 0
+	var batch []interface{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := recipeExportCollection.InsertMany(ctx, batch)
+		batch = nil
+		return err
+	}
+	for results.Next(ctx) {
+		var singleRecipe models.Recipe
+		if err = results.Decode(&singleRecipe); err != nil {
+			return nil, err
+		}
+		batch = append(batch, recipeExport{Id: fmt.Sprintf("%s-%d", job.Id, count), JobId: job.Id, Index: count, Recipe: singleRecipe})
+		count++
+		if len(batch) == exportBatchSize {
+			if err = flush(); err != nil {
+				return nil, err
+			}
+		}
+		if total > 0 {
+			progress(count * 100 / int(total))
+		}
+	}
+	if err = results.Err(); err != nil {
+		return nil, err
+	}
+	if err = flush(); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"count": count}, nil
+}
+
+// exportedRecipes reads back the recipes an export job saved, in the order
+// it saved them.
+func exportedRecipes(ctx context.Context, jobId string) ([]models.Recipe, error) {
+	results, err := recipeExportCollection.Find(ctx, bson.M{"jobId": jobId}, options.Find().SetSort(bson.D{{Key: "index", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+	recipes := []models.Recipe{}
+	for results.Next(ctx) {
+		var export recipeExport
+		if err = results.Decode(&export); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, export.Recipe)
+	}
+	return recipes, results.Err()
+}
+
+// importRecipesJob creates the recipes listed in the payload's "recipes"
+// field, authored by the job's owner.
+func importRecipesJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (map[string]interface{}, error) {
+	var payload struct {
+		Recipes []models.Recipe `json:"recipes"`
+	}
+	// Payload was stored as a generic document, round trip it through JSON
+	// to get typed recipes back.
+	raw, err := json.Marshal(job.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+
+	var importedIds []string
+	for i, recipe := range payload.Recipes {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Ids are derived from the whole job id so a retried import overwrites
+		// what an earlier attempt inserted instead of duplicating it, and
+		// never anything another import inserted.
+		recipe.Id = fmt.Sprintf("%s-%d", job.Id, i)
+		recipe.AuthorId = job.OwnerId
+		recipe.AverageRating, recipe.RatingCount = 0, 0
+		// Collaborators are only added by accepting an invitation, and only
+		// forking makes a recipe a fork. prepareRecipe makes it a draft.
+		recipe.Collaborators = nil
+		recipe.ParentRecipeId, recipe.ParentAuthorId = "", ""
+		if err = prepareRecipe(&recipe, nil); err != nil {
+			return nil, fmt.Errorf("recipe %d: %w", i, err)
+		}
+		if _, err = recipeCollection.ReplaceOne(ctx, bson.M{"_id": recipe.Id, "authorId": job.OwnerId}, recipe, options.Replace().SetUpsert(true)); err != nil {
+			return nil, err
+		}
+		queueUnmatchedIngredients(ctx, recipe.Id, recipe.Ingredients, nil)
+		publishRecipeWebhook(ctx, models.WebhookRecipeCreated, map[string]interface{}{"recipe": recipe})
+		publishRecipeChange(events.RecipeCreated, recipe.Id, &recipe)
+		importedIds = append(importedIds, recipe.Id)
+		progress((i + 1) * 100 / len(payload.Recipes))
+	}
+	return map[string]interface{}{"count": len(importedIds), "recipeIds": importedIds}, nil
+}
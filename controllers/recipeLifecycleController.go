@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/events"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/exp/slices"
+)
+
+// PublishRecipe validates a draft or archived recipe and publishes it. The
+// body may choose the visibility it's published with, a private draft is
+// made public otherwise.
+func PublishRecipe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var requestBody struct {
+			Visibility string `json:"visibility"`
+		}
+		defer cancel()
+
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&requestBody); err != nil {
+				c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+				return
+			}
+		}
+		recipe, ok := findAuthoredRecipe(ctx, c, &keycloakUser)
+		if !ok {
+			return
+		}
+		if recipe.Status == models.StatusPublished || recipe.Status == "" {
+			c.JSON(http.StatusConflict, responses.RecipeResponse{Status: http.StatusConflict, Message: "error", Data: map[string]interface{}{"data": "recipe with ID " + recipe.Id + " is already published"}})
+			return
+		}
+
+		visibility := strings.ToLower(strings.TrimSpace(requestBody.Visibility))
+		if visibility == "" {
+			visibility = recipe.Visibility
+			if visibility == models.VisibilityPrivate {
+				visibility = models.VisibilityPublic
+			}
+		}
+		if !slices.Contains(models.Visibilities, visibility) {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "visibility must be one of " + strings.Join(models.Visibilities, ", ")}})
+			return
+		}
+		if err := validateForPublishing(recipe); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, responses.RecipeResponse{Status: http.StatusUnprocessableEntity, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		// Republishing an archived recipe keeps its original publish date
+		now := time.Now().UTC()
+		if recipe.PublishedAt == nil {
+			recipe.PublishedAt = &now
+		}
+		recipe.Status, recipe.Visibility, recipe.ArchivedAt = models.StatusPublished, visibility, nil
+		update := bson.M{
+			"$set":   bson.M{"status": recipe.Status, "visibility": recipe.Visibility, "publishedAt": recipe.PublishedAt},
+			"$unset": bson.M{"archivedAt": ""},
+		}
+		if _, err := recipeCollection.UpdateByID(ctx, recipe.Id, update); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		log.Println("Published recipe with ID ", recipe.Id, " as ", recipe.Visibility)
+		recordRecipeEvent(ctx, models.FeedRecipePublished, recipe)
+		publishRecipeWebhook(ctx, models.WebhookRecipeUpdated, map[string]interface{}{"recipe": recipe})
+		publishRecipeChange(events.RecipeUpdated, recipe.Id, &recipe)
+		recordRecipeUpdate(ctx, recipe, keycloakUser.Sub)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully published recipe with ID " + recipe.Id, Data: map[string]interface{}{"data": recipe}})
+	}
+}
+
+// ArchiveRecipe takes a recipe out of listings, search and recommendations
+// while keeping it reachable for everyone who favorited it.
+func ArchiveRecipe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		recipe, ok := findAuthoredRecipe(ctx, c, &keycloakUser)
+		if !ok {
+			return
+		}
+		if recipe.Status == models.StatusArchived {
+			c.JSON(http.StatusConflict, responses.RecipeResponse{Status: http.StatusConflict, Message: "error", Data: map[string]interface{}{"data": "recipe with ID " + recipe.Id + " is already archived"}})
+			return
+		}
+
+		now := time.Now().UTC()
+		recipe.Status, recipe.ArchivedAt = models.StatusArchived, &now
+		update := bson.M{"$set": bson.M{"status": recipe.Status, "archivedAt": recipe.ArchivedAt}}
+		if _, err := recipeCollection.UpdateByID(ctx, recipe.Id, update); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		log.Println("Archived recipe with ID ", recipe.Id)
+		publishRecipeWebhook(ctx, models.WebhookRecipeUpdated, map[string]interface{}{"recipe": recipe})
+		publishRecipeChange(events.RecipeUpdated, recipe.Id, &recipe)
+		recordRecipeUpdate(ctx, recipe, keycloakUser.Sub)
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully archived recipe with ID " + recipe.Id, Data: map[string]interface{}{"data": recipe}})
+	}
+}
+
+// findAuthoredRecipe loads the recipe in the path, writing a 404 when the
+// user can't see it and a 403 when they can but didn't write it.
+func findAuthoredRecipe(ctx context.Context, c *gin.Context, keycloakUser *models.KeycloakUser) (models.Recipe, bool) {
+	recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), keycloakUser)
+	if !ok {
+		return recipe, false
+	}
+	if recipe.AuthorId != keycloakUser.Sub {
+		c.JSON(http.StatusForbidden, responses.RecipeResponse{Status: http.StatusForbidden, Message: "error", Data: map[string]interface{}{"data": "only the author can change the status of recipe with ID " + recipe.Id}})
+		return recipe, false
+	}
+	return recipe, true
+}
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hopk8412/table-recipes-api/events"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The functions in this file do what the REST handlers do without a gin
+// context, so the GraphQL and gRPC APIs can share them.
+
+// serviceError is an error caused by the request rather than the server,
+// with the status the REST API answers it with.
+type serviceError struct {
+	status  int
+	message string
+}
+
+func (err *serviceError) Error() string {
+	return err.message
+}
+
+func newServiceError(status int, message string) error {
+	return &serviceError{status: status, message: message}
+}
+
+// respondWithError writes err the way handlers write errors: with its status
+// if it's a serviceError, as a 500 otherwise.
+func respondWithError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	var requestErr *serviceError
+	if errors.As(err, &requestErr) {
+		status = requestErr.status
+	}
+	c.JSON(status, responses.RecipeResponse{Status: status, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+}
+
+// viewableRecipe loads a recipe the viewer is allowed to see. Recipes that
+// don't exist and other people's private ones are both not found.
+func viewableRecipe(ctx context.Context, recipeId string, viewer *models.KeycloakUser) (models.Recipe, error) {
+	var recipe models.Recipe
+	err := recipeCollection.FindOne(ctx, andFilter([]bson.M{{"_id": recipeId}, accessibleFilter(viewer)})).Decode(&recipe)
+	if err == mongo.ErrNoDocuments {
+		return recipe, newServiceError(http.StatusNotFound, "no recipe with ID "+recipeId)
+	}
+	return recipe, err
+}
+
+// listingFilter narrows conditions down to the recipes the viewer may find
+// in listings, with their saved preferences applied if they opted in and
+// applyPreferences is set.
+func listingFilter(ctx context.Context, viewer *models.KeycloakUser, applyPreferences bool, conditions []bson.M) (bson.M, error) {
+	var filters []bson.M
+	if applyPreferences {
+		var err error
+		if filters, err = userPreferenceConditions(ctx, viewer); err != nil {
+			return nil, err
+		}
+	}
+	filters = append(filters, discoverableFilter(viewer))
+	return andFilter(append(filters, conditions...)), nil
+}
+
+// createRecipe saves a new recipe by the user with the content fields of
+// recipe.
+func createRecipe(ctx context.Context, keycloakUser models.KeycloakUser, recipe models.Recipe) (models.Recipe, error) {
+	newRecipe := models.Recipe{
+		Id:               primitive.NewObjectID().Hex(),
+		Title:            recipe.Title,
+		Ingredients:      recipe.Ingredients,
+		Instructions:     recipe.Instructions,
+		AuthorId:         keycloakUser.Sub,
+		ImageLinks:       recipe.ImageLinks,
+		Servings:         recipe.Servings,
+		DietaryTags:      recipe.DietaryTags,
+		Cuisine:          recipe.Cuisine,
+		Course:           recipe.Course,
+		Difficulty:       recipe.Difficulty,
+		Yield:            recipe.Yield,
+		PrepTime:         recipe.PrepTime,
+		CookTime:         recipe.CookTime,
+		TotalTime:        recipe.TotalTime,
+		TotalTimeMinutes: recipe.TotalTimeMinutes,
+		Visibility:       recipe.Visibility,
+	}
+	if err := prepareRecipe(&newRecipe, nil); err != nil {
+		return newRecipe, newServiceError(http.StatusBadRequest, err.Error())
+	}
+	if _, err := recipeCollection.InsertOne(ctx, newRecipe); err != nil {
+		return newRecipe, err
+	}
+	recordDisplayName(ctx, keycloakUser)
+	queueUnmatchedIngredients(ctx, newRecipe.Id, newRecipe.Ingredients, nil)
+	publishRecipeWebhook(ctx, models.WebhookRecipeCreated, map[string]interface{}{"recipe": newRecipe})
+	publishRecipeChange(events.RecipeCreated, newRecipe.Id, &newRecipe)
+	return newRecipe, nil
+}
+
+// updateRecipe replaces the content of a recipe the user may edit with the
+// content fields of changes. Only the author may change its visibility.
+func updateRecipe(ctx context.Context, keycloakUser models.KeycloakUser, recipeId string, changes models.Recipe) (models.Recipe, error) {
+	recipe, err := viewableRecipe(ctx, recipeId, &keycloakUser)
+	if err != nil {
+		return recipe, err
+	}
+	if !canEdit(recipe, keycloakUser.Sub) {
+		return recipe, newServiceError(http.StatusForbidden, "user with ID "+keycloakUser.Sub+" can't edit recipe with ID "+recipeId)
+	}
+	if recipe.Status == models.StatusArchived {
+		return recipe, newServiceError(http.StatusConflict, "recipe with ID "+recipeId+" is archived, publish it again before editing")
+	}
+	// Editors can change what's in the recipe, only the author decides
+	// who gets to see it
+	if changes.Visibility != "" && changes.Visibility != recipe.Visibility && recipe.AuthorId != keycloakUser.Sub {
+		return recipe, newServiceError(http.StatusForbidden, "only the author can change the visibility of recipe with ID "+recipeId)
+	}
+
+	updatedRecipeData := models.Recipe{
+		Id:               recipeId,
+		Title:            changes.Title,
+		Ingredients:      changes.Ingredients,
+		Instructions:     changes.Instructions,
+		AuthorId:         recipe.AuthorId,
+		ImageLinks:       changes.ImageLinks,
+		Servings:         changes.Servings,
+		DietaryTags:      changes.DietaryTags,
+		Cuisine:          changes.Cuisine,
+		Course:           changes.Course,
+		Difficulty:       changes.Difficulty,
+		Yield:            changes.Yield,
+		PrepTime:         changes.PrepTime,
+		CookTime:         changes.CookTime,
+		TotalTime:        changes.TotalTime,
+		TotalTimeMinutes: changes.TotalTimeMinutes,
+		AverageRating:    recipe.AverageRating,
+		RatingCount:      recipe.RatingCount,
+		ParentRecipeId:   recipe.ParentRecipeId,
+		ParentAuthorId:   recipe.ParentAuthorId,
+		Visibility:       changes.Visibility,
+		Status:           recipe.Status,
+		PublishedAt:      recipe.PublishedAt,
+		ArchivedAt:       recipe.ArchivedAt,
+		Collaborators:    recipe.Collaborators,
+	}
+	if updatedRecipeData.Visibility == "" {
+		updatedRecipeData.Visibility = recipe.Visibility
+	}
+	if err := prepareRecipe(&updatedRecipeData, &recipe); err != nil {
+		return recipe, newServiceError(http.StatusBadRequest, err.Error())
+	}
+
+	updates := bson.M{
+		"$set": bson.M{
+			"_id":                 recipeId,
+			"title":               updatedRecipeData.Title,
+			"ingredients":         updatedRecipeData.Ingredients,
+			"instructions":        updatedRecipeData.Instructions,
+			"authorId":            updatedRecipeData.AuthorId,
+			"imageLinks":          updatedRecipeData.ImageLinks,
+			"servings":            updatedRecipeData.Servings,
+			"nutrition":           updatedRecipeData.Nutrition,
+			"dietaryTags":         updatedRecipeData.DietaryTags,
+			"detectedDietaryTags": updatedRecipeData.DetectedDietaryTags,
+			"allergens":           updatedRecipeData.Allergens,
+			"cuisine":             updatedRecipeData.Cuisine,
+			"course":              updatedRecipeData.Course,
+			"difficulty":          updatedRecipeData.Difficulty,
+			"yield":               updatedRecipeData.Yield,
+			"prepTime":            updatedRecipeData.PrepTime,
+			"cookTime":            updatedRecipeData.CookTime,
+			"totalTime":           updatedRecipeData.TotalTime,
+			"totalTimeMinutes":    updatedRecipeData.TotalTimeMinutes,
+			"visibility":          updatedRecipeData.Visibility,
+		},
+	}
+	if _, err := recipeCollection.UpdateByID(ctx, recipeId, updates); err != nil {
+		return recipe, err
+	}
+	queueUnmatchedIngredients(ctx, recipeId, updatedRecipeData.Ingredients, recipe.Ingredients)
+	recordRecipeEvent(ctx, models.FeedRecipeUpdated, updatedRecipeData)
+	publishRecipeWebhook(ctx, models.WebhookRecipeUpdated, map[string]interface{}{"recipe": updatedRecipeData})
+	publishRecipeChange(events.RecipeUpdated, recipeId, &updatedRecipeData)
+	recordRecipeUpdate(ctx, updatedRecipeData, keycloakUser.Sub)
+	return updatedRecipeData, nil
+}
+
+// deleteRecipe deletes a recipe, which only its author may do.
+func deleteRecipe(ctx context.Context, keycloakUser models.KeycloakUser, recipeId string) (*mongo.DeleteResult, error) {
+	recipe, err := viewableRecipe(ctx, recipeId, &keycloakUser)
+	if err != nil {
+		return nil, err
+	}
+	if recipe.AuthorId != keycloakUser.Sub {
+		return nil, newServiceError(http.StatusForbidden, "only the author can delete recipe with ID "+recipeId)
+	}
+	result, err := recipeCollection.DeleteOne(ctx, bson.M{"_id": recipeId})
+	if err != nil {
+		return nil, err
+	}
+	revokeRecipeInvitations(ctx, recipeId)
+	publishRecipeWebhook(ctx, models.WebhookRecipeDeleted, map[string]interface{}{"recipeId": recipeId, "authorId": recipe.AuthorId})
+	publishRecipeChange(events.RecipeDeleted, recipeId, nil)
+	return result, nil
+}
+
+// setFavorite adds a recipe the user can see to their favorites, or removes
+// one. Both are single updates, so two requests at once can't undo each
+// other.
+func setFavorite(ctx context.Context, keycloakUser models.KeycloakUser, recipeId string, favorite bool) (*mongo.UpdateResult, error) {
+	var recipe models.Recipe
+	update := bson.M{"$pull": bson.M{"favoriteRecipes": recipeId}}
+	if favorite {
+		var err error
+		if recipe, err = viewableRecipe(ctx, recipeId, &keycloakUser); err != nil {
+			return nil, err
+		}
+		update = bson.M{"$addToSet": bson.M{"favoriteRecipes": recipeId}}
+	}
+
+	result, err := usersCollection.UpdateOne(ctx, bson.M{"_id": keycloakUser.Sub}, update, options.Update().SetUpsert(favorite))
+	if err != nil {
+		return nil, err
+	}
+	if favorite && (result.ModifiedCount > 0 || result.UpsertedCount > 0) {
+		notifyFavorite(ctx, keycloakUser, recipe)
+		publishWebhookEvent(ctx, models.WebhookFavoriteAdded, map[string]interface{}{"recipeId": recipe.Id, "userId": keycloakUser.Sub})
+	}
+	return result, nil
+}
+
+// MigrateFavorites moves the favorites users were created with before
+// MongoUser had a bson tag, under the lowercased favoriterecipes, into
+// favoriteRecipes next to any added since. It only touches users that still
+// have the old field, so it's safe to call on every startup.
+func MigrateFavorites(ctx context.Context) error {
+	_, err := usersCollection.UpdateMany(ctx, bson.M{"favoriterecipes": bson.M{"$exists": true}}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"favoriteRecipes": bson.M{"$setUnion": bson.A{
+			bson.M{"$ifNull": bson.A{"$favoriteRecipes", bson.A{}}},
+			bson.M{"$ifNull": bson.A{"$favoriterecipes", bson.A{}}},
+		}}}}},
+		{{Key: "$unset", Value: "favoriterecipes"}},
+	})
+	return err
+}
+
+// favoriteRecipeIds is the user's favorites, empty for users who never
+// favorited anything.
+func favoriteRecipeIds(ctx context.Context, userId string) ([]string, error) {
+	var mongoUser models.MongoUser
+	err := usersCollection.FindOne(ctx, bson.M{"_id": userId}).Decode(&mongoUser)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return mongoUser.FavoriteRecipes, err
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/dietary"
+	"github.com/hopk8412/table-recipes-api/durations"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/nutrition"
+
+	"golang.org/x/exp/slices"
+)
+
+// prepareRecipe validates a recipe about to be saved and fills in every
+// field derived from the others. previous is the stored version when
+// updating, so expensive derived fields are only recalculated when what they
+// depend on changed.
+func prepareRecipe(recipe *models.Recipe, previous *models.Recipe) error {
+	if err := validateDietary(recipe.DietaryTags, nil); err != nil {
+		return err
+	}
+	if err := validateRecipeMetadata(recipe); err != nil {
+		return err
+	}
+	if err := validateSteps(recipe); err != nil {
+		return err
+	}
+	// New recipes stay with their author until they choose to share them
+	if previous == nil {
+		recipe.Status = models.StatusDraft
+		recipe.PublishedAt, recipe.ArchivedAt = nil, nil
+		if recipe.Visibility == "" {
+			recipe.Visibility = models.VisibilityPrivate
+		}
+	}
+	annotateSteps(recipe.Instructions)
+
+	recipe.DetectedDietaryTags, recipe.Allergens = dietary.Detect(recipe.Ingredients)
+	recipe.DietaryTags = dietary.Consistent(recipe.DietaryTags, recipe.Ingredients)
+
+	if previous != nil && previous.Nutrition != nil && recipe.Servings == previous.Servings && slices.Equal(recipe.Ingredients, previous.Ingredients) {
+		recipe.Nutrition = previous.Nutrition
+	} else {
+		if previous != nil {
+			log.Println("Ingredients or servings changed, recalculating nutrition for recipe with ID: ", recipe.Id)
+		}
+		recipe.Nutrition = nutrition.Calculate(recipe.Ingredients, recipe.Servings)
+	}
+	return nil
+}
+
+// validateRecipeMetadata checks the timing, yield and classification fields
+// and normalizes them. TotalTime defaults to PrepTime plus CookTime, and
+// TotalTimeMinutes is always derived from it.
+func validateRecipeMetadata(recipe *models.Recipe) error {
+	var times [3]time.Duration
+	for i, field := range []struct {
+		name  string
+		value *string
+	}{{"prepTime", &recipe.PrepTime}, {"cookTime", &recipe.CookTime}, {"totalTime", &recipe.TotalTime}} {
+		if *field.value == "" {
+			continue
+		}
+		parsed, err := durations.Parse(*field.value)
+		if err != nil {
+			return errors.New(field.name + " must be an ISO 8601 duration like PT1H30M")
+		}
+		times[i] = parsed
+		*field.value = durations.Format(parsed)
+	}
+	prep, cook, total := times[0], times[1], times[2]
+	if recipe.TotalTime == "" && prep+cook > 0 {
+		total = prep + cook
+		recipe.TotalTime = durations.Format(total)
+	} else if recipe.TotalTime == "" && recipe.TotalTimeMinutes > 0 {
+		// Older clients only send totalTimeMinutes
+		total = time.Duration(recipe.TotalTimeMinutes) * time.Minute
+		recipe.TotalTime = durations.Format(total)
+	}
+	if total > 0 && total < prep+cook {
+		return errors.New("totalTime cannot be shorter than prepTime plus cookTime")
+	}
+	recipe.TotalTimeMinutes = int(total / time.Minute)
+
+	if recipe.Servings < 0 {
+		return errors.New("servings cannot be negative")
+	}
+	if recipe.Yield != nil {
+		recipe.Yield.Unit = strings.TrimSpace(recipe.Yield.Unit)
+		if recipe.Yield.Quantity <= 0 || recipe.Yield.Unit == "" {
+			return errors.New("yield needs a positive quantity and a unit")
+		}
+	}
+
+	recipe.Difficulty = strings.ToLower(strings.TrimSpace(recipe.Difficulty))
+	if recipe.Difficulty != "" && !slices.Contains(models.Difficulties, recipe.Difficulty) {
+		return errors.New("difficulty must be one of " + strings.Join(models.Difficulties, ", "))
+	}
+	recipe.Course = strings.ToLower(strings.TrimSpace(recipe.Course))
+	if recipe.Course != "" && !slices.Contains(models.Courses, recipe.Course) {
+		return errors.New("course must be one of " + strings.Join(models.Courses, ", "))
+	}
+	recipe.Cuisine = strings.TrimSpace(recipe.Cuisine)
+
+	recipe.Visibility = strings.ToLower(strings.TrimSpace(recipe.Visibility))
+	if recipe.Visibility != "" && !slices.Contains(models.Visibilities, recipe.Visibility) {
+		return errors.New("visibility must be one of " + strings.Join(models.Visibilities, ", "))
+	}
+	return nil
+}
+
+// validateSteps checks every step has text, a valid timer and only refers to
+// ingredients the recipe has.
+func validateSteps(recipe *models.Recipe) error {
+	for i := range recipe.Instructions {
+		step := &recipe.Instructions[i]
+		position := strconv.Itoa(i + 1)
+		step.Text = strings.TrimSpace(step.Text)
+		step.Section = strings.TrimSpace(step.Section)
+		if step.Text == "" {
+			return errors.New("step " + position + " has no text")
+		}
+		if step.Timer != "" {
+			timer, err := durations.Parse(step.Timer)
+			if err != nil || timer <= 0 {
+				return errors.New("step " + position + " timer must be an ISO 8601 duration like PT25M")
+			}
+			step.Timer = durations.Format(timer)
+		}
+		for _, ingredient := range step.Ingredients {
+			if ingredient < 0 || ingredient >= len(recipe.Ingredients) {
+				return errors.New("step " + position + " refers to ingredient " + strconv.Itoa(ingredient) + " which doesn't exist")
+			}
+		}
+	}
+	return nil
+}
+
+// annotateSteps finds the durations mentioned in each step so cooking mode
+// clients can offer timers even when the author didn't set one.
+func annotateSteps(steps []models.Step) {
+	for i := range steps {
+		steps[i].DetectedTimers = nil
+		for _, match := range durations.Find(steps[i].Text) {
+			timer := models.StepTimer{Text: match.Text, Duration: durations.Format(match.Min)}
+			if match.Max > match.Min {
+				timer.MaxDuration = durations.Format(match.Max)
+			}
+			steps[i].DetectedTimers = append(steps[i].DetectedTimers, timer)
+		}
+	}
+}
+
+// validateForPublishing checks a recipe is complete enough for others to
+// cook from. Drafts can be saved half finished, publishing can't.
+func validateForPublishing(recipe models.Recipe) error {
+	if strings.TrimSpace(recipe.Title) == "" {
+		return errors.New("a title is required to publish")
+	}
+	if len(recipe.Ingredients) == 0 {
+		return errors.New("at least one ingredient is required to publish")
+	}
+	if len(recipe.Instructions) == 0 {
+		return errors.New("at least one step is required to publish")
+	}
+	if configs.PublishRequiresImage() && strings.TrimSpace(recipe.ImageLinks) == "" {
+		return errors.New("an image is required to publish")
+	}
+	return prepareRecipe(&recipe, &recipe)
+}
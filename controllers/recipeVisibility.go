@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Recipes without a visibility or status predate them and stay public and
+// published.
+var (
+	publicVisibility = bson.M{"visibility": bson.M{"$in": bson.A{models.VisibilityPublic, "", nil}}}
+	publishedStatus  = bson.M{"status": bson.M{"$in": bson.A{models.StatusPublished, "", nil}}}
+)
+
+// discoverableFilter matches the recipes that may show up in listings,
+// search and recommendations: published public ones, plus everything the
+// viewer wrote that isn't archived. viewer is nil for anonymous requests.
+func discoverableFilter(viewer *models.KeycloakUser) bson.M {
+	public := bson.M{"$and": bson.A{publicVisibility, publishedStatus}}
+	if viewer == nil {
+		return public
+	}
+	return bson.M{"$or": bson.A{public, bson.M{"authorId": viewer.Sub, "status": bson.M{"$ne": models.StatusArchived}}}}
+}
+
+// accessibleFilter matches the recipes the viewer may open directly, which
+// also includes unlisted and archived ones, and anything shared with them.
+func accessibleFilter(viewer *models.KeycloakUser) bson.M {
+	shared := bson.M{"visibility": bson.M{"$ne": models.VisibilityPrivate}, "status": bson.M{"$ne": models.StatusDraft}}
+	if viewer == nil {
+		return shared
+	}
+	return bson.M{"$or": bson.A{shared, bson.M{"authorId": viewer.Sub}, bson.M{"collaborators.userId": viewer.Sub}}}
+}
+
+// canView is accessibleFilter for a recipe that's already been loaded.
+func canView(recipe models.Recipe, viewer *models.KeycloakUser) bool {
+	if viewer != nil && (viewer.Sub == recipe.AuthorId || collaboratorRole(recipe, viewer.Sub) != "") {
+		return true
+	}
+	return recipe.Visibility != models.VisibilityPrivate && recipe.Status != models.StatusDraft
+}
+
+// canEdit is whether the user may change the recipe's content: its author
+// and editors can, viewers and everyone else can't.
+func canEdit(recipe models.Recipe, userId string) bool {
+	return recipe.AuthorId == userId || collaboratorRole(recipe, userId) == models.CollaboratorEditor
+}
+
+// collaboratorRole is the user's role on the recipe, or "" if the recipe
+// isn't shared with them.
+func collaboratorRole(recipe models.Recipe, userId string) string {
+	for _, collaborator := range recipe.Collaborators {
+		if collaborator.UserId == userId {
+			return collaborator.Role
+		}
+	}
+	return ""
+}
+
+// isDiscoverable is discoverableFilter for a recipe that's already been
+// loaded.
+func isDiscoverable(recipe models.Recipe, viewer *models.KeycloakUser) bool {
+	if viewer != nil && viewer.Sub == recipe.AuthorId {
+		return recipe.Status != models.StatusArchived
+	}
+	public := recipe.Visibility == "" || recipe.Visibility == models.VisibilityPublic
+	published := recipe.Status == "" || recipe.Status == models.StatusPublished
+	return public && published
+}
@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/ingredients"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultRecommendationLimit = 10
+	// Caps on how much gets pulled into memory to score.
+	maxRecommendationCandidates = 500
+	maxSimilarUsers             = 500
+	// How much of the score comes from the recipe itself versus what
+	// similar users favorited.
+	contentWeight       = 0.6
+	collaborativeWeight = 0.4
+	// How much of the content score comes from shared ingredients, dietary
+	// tags and cuisine.
+	ingredientSimilarityWeight = 0.6
+	tagSimilarityWeight        = 0.25
+	cuisineSimilarityWeight    = 0.15
+)
+
+// GetUserRecommendations suggests recipes similar to the user's favorites,
+// blending content similarity (shared ingredients, dietary tags and cuisine)
+// with "users who favorited X also favorited Y". Recipes the user already
+// favorited or wrote are never suggested.
+func GetUserRecommendations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireSelf(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var mongoUser models.MongoUser
+		defer cancel()
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultRecommendationLimit)))
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": "limit must be a positive number"}})
+			return
+		}
+
+		err = usersCollection.FindOne(ctx, bson.M{"_id": c.Param("id")}).Decode(&mongoUser)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		if mongoUser.FavoriteRecipes == nil {
+			mongoUser.FavoriteRecipes = []string{}
+		}
+		favorites, err := findRecipes(ctx, andFilter([]bson.M{{"_id": bson.M{"$in": mongoUser.FavoriteRecipes}}, accessibleFilter(&keycloakUser)}), nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		profile := newTasteProfile(favorites)
+
+		alsoFavorited, err := alsoFavoritedCounts(ctx, mongoUser)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		// Candidates are anything similar users favorited plus anything
+		// sharing a cuisine, tag or main ingredient with the favorites.
+		similar := []bson.M{}
+		if len(alsoFavorited) > 0 {
+			ids := make([]string, 0, len(alsoFavorited))
+			for id := range alsoFavorited {
+				ids = append(ids, id)
+			}
+			similar = append(similar, bson.M{"_id": bson.M{"$in": ids}})
+		}
+		similar = append(similar, profile.candidateConditions()...)
+		conditions := []bson.M{
+			{"_id": bson.M{"$nin": mongoUser.FavoriteRecipes}},
+			{"authorId": bson.M{"$ne": c.Param("id")}},
+			discoverableFilter(nil),
+		}
+		if len(similar) > 0 {
+			conditions = append(conditions, bson.M{"$or": similar})
+		}
+		if mongoUser.Preferences != nil && mongoUser.Preferences.ApplyToListings && c.Query("applyPreferences") != "false" {
+			conditions = append(conditions, preferenceConditions(mongoUser.Preferences)...)
+		}
+		// With nothing to go on yet, fall back to the best rated recipes.
+		findOptions := options.Find().SetLimit(maxRecommendationCandidates).SetSort(bson.D{{Key: "averageRating", Value: -1}, {Key: "ratingCount", Value: -1}})
+		candidates, err := findRecipes(ctx, andFilter(conditions), findOptions)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		maxAlsoFavorited := 0
+		for _, count := range alsoFavorited {
+			if count > maxAlsoFavorited {
+				maxAlsoFavorited = count
+			}
+		}
+		recommendations := []models.Recommendation{}
+		for _, candidate := range candidates {
+			score, reasons := profile.similarity(candidate)
+			score *= contentWeight
+			if count := alsoFavorited[candidate.Id]; count > 0 {
+				score += collaborativeWeight * float64(count) / float64(maxAlsoFavorited)
+				reasons = append(reasons, strconv.Itoa(count)+" people with similar favorites saved this")
+			}
+			if len(favorites) > 0 && score == 0 {
+				continue
+			}
+			recommendations = append(recommendations, models.Recommendation{Recipe: candidate, Score: math.Round(score*1000) / 1000, Reasons: reasons})
+		}
+		sort.SliceStable(recommendations, func(i, j int) bool {
+			return recommendations[i].Score > recommendations[j].Score
+		})
+		if len(recommendations) > limit {
+			recommendations = recommendations[:limit]
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched recommendations for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": recommendations}})
+	}
+}
+
+func findRecipes(ctx context.Context, filter interface{}, findOptions *options.FindOptions) ([]models.Recipe, error) {
+	recipes := []models.Recipe{}
+	results, err := recipeCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+	if err = results.All(ctx, &recipes); err != nil {
+		return nil, err
+	}
+	return recipes, nil
+}
+
+// alsoFavoritedCounts counts, for each recipe the user hasn't favorited, how
+// many users sharing at least one favorite with them favorited it.
+func alsoFavoritedCounts(ctx context.Context, mongoUser models.MongoUser) (map[string]int, error) {
+	counts := map[string]int{}
+	if len(mongoUser.FavoriteRecipes) == 0 {
+		return counts, nil
+	}
+	mine := map[string]bool{}
+	for _, id := range mongoUser.FavoriteRecipes {
+		mine[id] = true
+	}
+
+	filter := andFilter([]bson.M{{"_id": bson.M{"$ne": mongoUser.Id}}, {"favoriteRecipes": bson.M{"$in": mongoUser.FavoriteRecipes}}})
+	results, err := usersCollection.Find(ctx, filter, options.Find().SetLimit(maxSimilarUsers))
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+	for results.Next(ctx) {
+		var other models.MongoUser
+		if err = results.Decode(&other); err != nil {
+			return nil, err
+		}
+		for _, id := range other.FavoriteRecipes {
+			if !mine[id] {
+				counts[id]++
+			}
+		}
+	}
+	return counts, results.Err()
+}
+
+// tasteProfile summarizes a user's favorites for content similarity.
+type tasteProfile struct {
+	favoriteCount int
+	ingredients   map[string]int
+	tags          map[string]int
+	cuisines      map[string]int
+}
+
+func newTasteProfile(favorites []models.Recipe) tasteProfile {
+	profile := tasteProfile{
+		favoriteCount: len(favorites),
+		ingredients:   map[string]int{},
+		tags:          map[string]int{},
+		cuisines:      map[string]int{},
+	}
+	for _, recipe := range favorites {
+		for name := range recipeIngredientSet(recipe) {
+			profile.ingredients[name]++
+		}
+		for tag := range recipeTagSet(recipe) {
+			profile.tags[tag]++
+		}
+		if recipe.Cuisine != "" {
+			profile.cuisines[strings.ToLower(recipe.Cuisine)]++
+		}
+	}
+	return profile
+}
+
+// candidateConditions narrows candidates down to recipes sharing something
+// with the favorites. Only the most common ingredients are used, to keep the
+// query small.
+func (profile tasteProfile) candidateConditions() []bson.M {
+	var conditions []bson.M
+	for cuisine := range profile.cuisines {
+		conditions = append(conditions, bson.M{"cuisine": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(cuisine) + "$", Options: "i"}})
+	}
+	var tags []string
+	for tag := range profile.tags {
+		tags = append(tags, tag)
+	}
+	if len(tags) > 0 {
+		// Detected tags cover every tag an author may keep, see
+		// dietary.Consistent
+		conditions = append(conditions, bson.M{"detectedDietaryTags": bson.M{"$in": tags}})
+	}
+	for _, name := range topKeys(profile.ingredients, 10) {
+		words := strings.Fields(name)
+		conditions = append(conditions, bson.M{"ingredients": primitive.Regex{Pattern: `\b` + regexp.QuoteMeta(words[len(words)-1]), Options: "i"}})
+	}
+	return conditions
+}
+
+// similarity scores a recipe from 0 to 1 against the profile: mostly shared
+// ingredients, then dietary tags, then cuisine.
+func (profile tasteProfile) similarity(recipe models.Recipe) (float64, []string) {
+	reasons := []string{}
+	if profile.favoriteCount == 0 {
+		return 0, reasons
+	}
+	ingredientScore, shared := overlap(recipeIngredientSet(recipe), profile.ingredients)
+	if len(shared) > 0 {
+		if len(shared) > 3 {
+			shared = shared[:3]
+		}
+		reasons = append(reasons, "uses "+strings.Join(shared, ", ")+" like your favorites")
+	}
+	tagScore, sharedTags := overlap(recipeTagSet(recipe), profile.tags)
+	if len(sharedTags) > 0 {
+		reasons = append(reasons, "is "+strings.Join(sharedTags, ", "))
+	}
+	cuisineScore := 0.0
+	if count := profile.cuisines[strings.ToLower(recipe.Cuisine)]; count > 0 {
+		cuisineScore = float64(count) / float64(profile.favoriteCount)
+		reasons = append(reasons, recipe.Cuisine+" like "+strconv.Itoa(count)+" of your favorites")
+	}
+	return ingredientSimilarityWeight*ingredientScore + tagSimilarityWeight*tagScore + cuisineSimilarityWeight*cuisineScore, reasons
+}
+
+// overlap is the Jaccard similarity of the recipe's set with the set of
+// keys in the profile, plus the shared keys most common in the profile
+// first.
+func overlap(recipeSet map[string]bool, profileCounts map[string]int) (float64, []string) {
+	if len(recipeSet) == 0 || len(profileCounts) == 0 {
+		return 0, nil
+	}
+	var shared []string
+	for key := range recipeSet {
+		if profileCounts[key] > 0 {
+			shared = append(shared, key)
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if profileCounts[shared[i]] != profileCounts[shared[j]] {
+			return profileCounts[shared[i]] > profileCounts[shared[j]]
+		}
+		return shared[i] < shared[j]
+	})
+	union := len(recipeSet) + len(profileCounts) - len(shared)
+	return float64(len(shared)) / float64(union), shared
+}
+
+func recipeIngredientSet(recipe models.Recipe) map[string]bool {
+	set := map[string]bool{}
+	for _, line := range recipe.Ingredients {
+		if name := ingredients.Canonical(line); name != "" && !ingredients.IsStaple(name) {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+func recipeTagSet(recipe models.Recipe) map[string]bool {
+	set := map[string]bool{}
+	for _, tag := range recipe.DietaryTags {
+		set[tag] = true
+	}
+	for _, tag := range recipe.DetectedDietaryTags {
+		set[tag] = true
+	}
+	return set
+}
+
+func topKeys(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var reviewCollection *mongo.Collection = configs.GetCollection(configs.DB, "reviews")
+
+func GetRecipeReviews() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		recipe, ok := findViewableRecipe(ctx, c, c.Param("id"), optionalUser(c))
+		if !ok {
+			return
+		}
+		reviews := []models.Review{}
+		results, err := reviewCollection.Find(ctx, bson.M{"recipeId": recipe.Id}, options.Find().SetSort(bson.M{"updatedAt": -1}))
+		if err == nil {
+			err = results.All(ctx, &reviews)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched reviews of recipe with ID " + recipe.Id, Data: map[string]interface{}{"data": reviews}})
+	}
+}
+
+// PostRecipeReview rates a recipe from 1 to 5. Reviewing the same recipe
+// again replaces the earlier review. Authors can't review their own recipes.
+func PostRecipeReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var reviewRequest models.ReviewRequest
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&reviewRequest); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		review, err := reviewRecipe(ctx, keycloakUser, c.Param("id"), reviewRequest)
+		if err != nil {
+			respondWithError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully reviewed recipe with ID " + review.RecipeId, Data: map[string]interface{}{"data": review}})
+	}
+}
+
+// DeleteRecipeReview removes the caller's review of a recipe.
+func DeleteRecipeReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireUser(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := deleteReview(ctx, keycloakUser, c.Param("id"))
+		if err != nil {
+			respondWithError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully deleted review of recipe with ID " + c.Param("id"), Data: map[string]interface{}{"data": result}})
+	}
+}
+
+// reviewRecipe saves the user's review of a recipe they can see, replacing
+// any earlier one.
+func reviewRecipe(ctx context.Context, keycloakUser models.KeycloakUser, recipeId string, reviewRequest models.ReviewRequest) (models.Review, error) {
+	var review models.Review
+	if reviewRequest.Rating < 1 || reviewRequest.Rating > 5 {
+		return review, newServiceError(http.StatusBadRequest, "rating must be between 1 and 5")
+	}
+	recipe, err := viewableRecipe(ctx, recipeId, &keycloakUser)
+	if err != nil {
+		return review, err
+	}
+	if recipe.AuthorId == keycloakUser.Sub {
+		return review, newServiceError(http.StatusForbidden, "authors can't review their own recipes")
+	}
+
+	now := time.Now().UTC()
+	err = reviewCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": reviewId(keycloakUser.Sub, recipe.Id)},
+		bson.M{
+			"$set":         bson.M{"rating": reviewRequest.Rating, "comment": strings.TrimSpace(reviewRequest.Comment), "updatedAt": now},
+			"$setOnInsert": bson.M{"recipeId": recipe.Id, "userId": keycloakUser.Sub, "createdAt": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&review)
+	if err != nil {
+		return review, err
+	}
+	if err = updateRecipeRating(ctx, recipe.Id); err != nil {
+		return review, err
+	}
+	recordReviewEvent(ctx, review, recipe)
+	recordLiveRecipeEvent(ctx, models.RecipeEvent{RecipeId: recipe.Id, Type: models.RecipeEventReview, ActorId: keycloakUser.Sub, Review: &review})
+	// Changing an earlier review doesn't notify the author again
+	if review.CreatedAt.Equal(review.UpdatedAt) {
+		notify(ctx, models.Notification{
+			Type:        models.NotificationReviewCreated,
+			RecipientId: recipe.AuthorId,
+			ActorId:     keycloakUser.Sub,
+			ActorName:   keycloakUser.PreferredUsername,
+			RecipeId:    recipe.Id,
+			RecipeTitle: recipe.Title,
+			Rating:      review.Rating,
+			Comment:     review.Comment,
+		})
+	}
+	return review, nil
+}
+
+// deleteReview removes the user's review of a recipe, if they wrote one.
+func deleteReview(ctx context.Context, keycloakUser models.KeycloakUser, recipeId string) (*mongo.DeleteResult, error) {
+	result, err := reviewCollection.DeleteOne(ctx, bson.M{"_id": reviewId(keycloakUser.Sub, recipeId)})
+	if err == nil && result.DeletedCount > 0 {
+		err = updateRecipeRating(ctx, recipeId)
+	}
+	return result, err
+}
+
+func reviewId(userId string, recipeId string) string {
+	return userId + ":" + recipeId
+}
+
+// updateRecipeRating recalculates the averageRating and ratingCount kept on
+// the recipe from its reviews.
+func updateRecipeRating(ctx context.Context, recipeId string) error {
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"recipeId": recipeId}},
+		bson.M{"$group": bson.M{"_id": nil, "average": bson.M{"$avg": "$rating"}, "count": bson.M{"$sum": 1}}},
+	}
+	cursor, err := reviewCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var totals []struct {
+		Average float64 `bson:"average"`
+		Count   int     `bson:"count"`
+	}
+	if err = cursor.All(ctx, &totals); err != nil {
+		return err
+	}
+	update := bson.M{"$unset": bson.M{"averageRating": "", "ratingCount": ""}}
+	if len(totals) > 0 {
+		update = bson.M{"$set": bson.M{"averageRating": math.Round(totals[0].Average*100) / 100, "ratingCount": totals[0].Count}}
+	}
+	log.Println("Updating rating of recipe with ID ", recipeId)
+	_, err = recipeCollection.UpdateByID(ctx, recipeId, update)
+	return err
+}
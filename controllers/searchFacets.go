@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hopk8412/table-recipes-api/ingredients"
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/exp/slices"
+)
+
+// Thresholds offered as "4 & up" style rating refinements and "under 30
+// minutes" style time refinements.
+var ratingFacetThresholds = []int{4, 3, 2, 1}
+var totalTimeFacetThresholds = []int{15, 30, 60, 120}
+
+func validateSearchQuery(searchQuery models.SearchQuery) error {
+	if err := validateDietary(searchQuery.DietaryTags, searchQuery.ExcludeAllergens); err != nil {
+		return err
+	}
+	if searchQuery.MaxTotalTime < 0 {
+		return errors.New("maxTotalTime cannot be negative")
+	}
+	if searchQuery.MinRating < 0 || searchQuery.MinRating > 5 {
+		return errors.New("minRating must be between 0 and 5")
+	}
+	if searchQuery.Course != "" && !slices.Contains(models.Courses, strings.ToLower(searchQuery.Course)) {
+		return errors.New("course must be one of " + strings.Join(models.Courses, ", "))
+	}
+	if searchQuery.Difficulty != "" && !slices.Contains(models.Difficulties, strings.ToLower(searchQuery.Difficulty)) {
+		return errors.New("difficulty must be one of " + strings.Join(models.Difficulties, ", "))
+	}
+	return nil
+}
+
+// searchFacetNames are the facets searchFacets counts, each named after the
+// search filter it refines.
+var searchFacetNames = []string{"dietaryTags", "allergens", "cuisine", "course", "difficulty", "authorId", "minRating", "maxTotalTime"}
+
+// searchConditions turns every filter set on the query into a condition.
+func searchConditions(searchQuery models.SearchQuery) []bson.M {
+	conditions, facetConditions := searchFilters(searchQuery)
+	for _, name := range searchFacetNames {
+		conditions = append(conditions, facetConditions[name]...)
+	}
+	return conditions
+}
+
+// searchFilters splits the query's conditions into those without a facet
+// and those refining one, keyed by the facet's name.
+func searchFilters(searchQuery models.SearchQuery) ([]bson.M, map[string][]bson.M) {
+	conditions := []bson.M{}
+	if searchQuery.SearchTerm != "" {
+		conditions = append(conditions, bson.M{"title": primitive.Regex{Pattern: searchQuery.SearchTerm, Options: "i"}})
+	}
+	for _, ingredient := range searchQuery.IncludeIngredients {
+		conditions = append(conditions, bson.M{"ingredients": ingredientPattern(ingredient)})
+	}
+	for _, ingredient := range searchQuery.ExcludeIngredients {
+		conditions = append(conditions, bson.M{"ingredients": bson.M{"$not": ingredientPattern(ingredient)}})
+	}
+
+	facetConditions := map[string][]bson.M{
+		"dietaryTags": dietaryFilter(searchQuery.DietaryTags, nil),
+		"allergens":   dietaryFilter(nil, searchQuery.ExcludeAllergens),
+	}
+	if searchQuery.MaxTotalTime > 0 {
+		facetConditions["maxTotalTime"] = []bson.M{{"totalTimeMinutes": bson.M{"$gt": 0, "$lte": searchQuery.MaxTotalTime}}}
+	}
+	if searchQuery.Cuisine != "" {
+		facetConditions["cuisine"] = []bson.M{{"cuisine": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(searchQuery.Cuisine) + "$", Options: "i"}}}
+	}
+	if searchQuery.Course != "" {
+		facetConditions["course"] = []bson.M{{"course": strings.ToLower(searchQuery.Course)}}
+	}
+	if searchQuery.Difficulty != "" {
+		facetConditions["difficulty"] = []bson.M{{"difficulty": strings.ToLower(searchQuery.Difficulty)}}
+	}
+	if searchQuery.MinRating > 0 {
+		facetConditions["minRating"] = []bson.M{{"averageRating": bson.M{"$gte": searchQuery.MinRating}}}
+	}
+	if searchQuery.AuthorId != "" {
+		facetConditions["authorId"] = []bson.M{{"authorId": searchQuery.AuthorId}}
+	}
+	return conditions, facetConditions
+}
+
+// ingredientPattern matches ingredient lines mentioning the ingredient. When
+// it's in the catalog, any of its names will do, so searching for
+// "scallions" finds recipes using "green onions".
+func ingredientPattern(ingredient string) primitive.Regex {
+	names := []string{regexp.QuoteMeta(strings.TrimSpace(ingredient))}
+	catalog := ingredients.CurrentCatalog()
+	if entry := catalog.Lookup(ingredient); entry != nil {
+		for _, form := range catalog.Forms(entry) {
+			names = append(names, regexp.QuoteMeta(form))
+		}
+	}
+	return primitive.Regex{Pattern: `\b(` + strings.Join(names, "|") + `)`, Options: "i"}
+}
+
+// searchFacets counts how many of the recipes matching the search fall under
+// each value of every search filter, so clients can offer "Vegetarian (42)"
+// style refinements. Each facet is counted without its own filter, so
+// picking one cuisine still shows how many recipes the others have.
+// conditions are the ones that always apply, like visibility.
+func searchFacets(ctx context.Context, conditions []bson.M, searchQuery models.SearchQuery) (map[string][]models.FacetValue, error) {
+	queryConditions, facetConditions := searchFilters(searchQuery)
+	conditions = append(append([]bson.M{}, conditions...), queryConditions...)
+	countBy := func(field string) bson.A {
+		return bson.A{
+			bson.M{"$match": bson.M{field: bson.M{"$nin": bson.A{nil, ""}}}},
+			bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		}
+	}
+	facetPipelines := bson.M{
+		"dietaryTags": bson.A{
+			bson.M{"$project": bson.M{"tags": bson.M{"$setUnion": bson.A{
+				bson.M{"$ifNull": bson.A{"$dietaryTags", bson.A{}}},
+				bson.M{"$ifNull": bson.A{"$detectedDietaryTags", bson.A{}}},
+			}}}},
+			bson.M{"$unwind": "$tags"},
+			bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		},
+		"allergens": bson.A{
+			bson.M{"$unwind": "$allergens"},
+			bson.M{"$group": bson.M{"_id": "$allergens", "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		},
+		"cuisine":    countBy("cuisine"),
+		"course":     countBy("course"),
+		"difficulty": countBy("difficulty"),
+		"authorId":   countBy("authorId"),
+		"minRating": bson.A{
+			bson.M{"$match": bson.M{"averageRating": bson.M{"$gt": 0}}},
+			bson.M{"$project": bson.M{"_id": bson.M{"$floor": "$averageRating"}}},
+			bson.M{"$group": bson.M{"_id": "$_id", "count": bson.M{"$sum": 1}}},
+		},
+		"maxTotalTime": bson.A{
+			bson.M{"$match": bson.M{"totalTimeMinutes": bson.M{"$gt": 0}}},
+			bson.M{"$bucket": bson.M{
+				"groupBy":    "$totalTimeMinutes",
+				"boundaries": totalTimeBucketBoundaries(),
+				"default":    "longer",
+			}},
+		},
+	}
+	for _, name := range searchFacetNames {
+		var otherConditions []bson.M
+		for _, other := range searchFacetNames {
+			if other != name {
+				otherConditions = append(otherConditions, facetConditions[other]...)
+			}
+		}
+		if len(otherConditions) > 0 {
+			facetPipelines[name] = append(bson.A{bson.M{"$match": andFilter(otherConditions)}}, facetPipelines[name].(bson.A)...)
+		}
+	}
+	pipeline := bson.A{
+		bson.M{"$match": andFilter(conditions)},
+		bson.M{"$facet": facetPipelines},
+	}
+
+	cursor, err := recipeCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var facetResults []struct {
+		DietaryTags []models.FacetValue `bson:"dietaryTags"`
+		Allergens   []models.FacetValue `bson:"allergens"`
+		Cuisine     []models.FacetValue `bson:"cuisine"`
+		Course      []models.FacetValue `bson:"course"`
+		Difficulty  []models.FacetValue `bson:"difficulty"`
+		AuthorId    []models.FacetValue `bson:"authorId"`
+		Ratings     []struct {
+			Floor float64 `bson:"_id"`
+			Count int     `bson:"count"`
+		} `bson:"minRating"`
+		TotalTimes []struct {
+			LowerBound interface{} `bson:"_id"`
+			Count      int         `bson:"count"`
+		} `bson:"maxTotalTime"`
+	}
+	if err = cursor.All(ctx, &facetResults); err != nil {
+		return nil, err
+	}
+	if len(facetResults) == 0 {
+		return map[string][]models.FacetValue{}, nil
+	}
+	result := facetResults[0]
+
+	// Rating and time refinements are cumulative: "3 & up" includes the 4s,
+	// "under 60 minutes" includes everything under 30.
+	ratings := []models.FacetValue{}
+	for _, threshold := range ratingFacetThresholds {
+		count := 0
+		for _, bucket := range result.Ratings {
+			if bucket.Floor >= float64(threshold) {
+				count += bucket.Count
+			}
+		}
+		ratings = append(ratings, models.FacetValue{Value: strconv.Itoa(threshold), Count: count})
+	}
+	totalTimes := []models.FacetValue{}
+	for _, threshold := range totalTimeFacetThresholds {
+		count := 0
+		for _, bucket := range result.TotalTimes {
+			// Bucket ids are the lower bounds, or "longer" for the default
+			var lowerBound int64
+			switch bound := bucket.LowerBound.(type) {
+			case int32:
+				lowerBound = int64(bound)
+			case int64:
+				lowerBound = bound
+			default:
+				continue
+			}
+			if lowerBound <= int64(threshold) {
+				count += bucket.Count
+			}
+		}
+		totalTimes = append(totalTimes, models.FacetValue{Value: strconv.Itoa(threshold), Count: count})
+	}
+
+	facets := map[string][]models.FacetValue{
+		"dietaryTags":  emptyIfNil(result.DietaryTags),
+		"allergens":    emptyIfNil(result.Allergens),
+		"cuisine":      emptyIfNil(result.Cuisine),
+		"course":       emptyIfNil(result.Course),
+		"difficulty":   emptyIfNil(result.Difficulty),
+		"authorId":     emptyIfNil(result.AuthorId),
+		"minRating":    ratings,
+		"maxTotalTime": totalTimes,
+	}
+	return facets, nil
+}
+
+// totalTimeBucketBoundaries buckets times as 1-15, 16-30, 31-60 and so on,
+// with anything past the last threshold in the "longer" bucket.
+func totalTimeBucketBoundaries() bson.A {
+	boundaries := bson.A{1}
+	for _, threshold := range totalTimeFacetThresholds {
+		boundaries = append(boundaries, threshold+1)
+	}
+	return boundaries
+}
+
+func emptyIfNil(values []models.FacetValue) []models.FacetValue {
+	if values == nil {
+		return []models.FacetValue{}
+	}
+	return values
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// counterCollection holds one document per sequence with the last number
+// handed out.
+var counterCollection *mongo.Collection = configs.GetCollection(configs.DB, "counters")
+
+type counter struct {
+	Value int64 `bson:"value"`
+}
+
+// nextSequence hands out the next number of the named sequence. Unlike
+// ObjectIDs and clocks, sequences are ordered across every copy of the API,
+// so streams can resume from them without skipping events.
+func nextSequence(ctx context.Context, name string) (int64, error) {
+	var next counter
+	err := counterCollection.FindOneAndUpdate(ctx, bson.M{"_id": name}, bson.M{"$inc": bson.M{"value": int64(1)}}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&next)
+	return next.Value, err
+}
+
+// currentSequence is the last number nextSequence handed out, 0 before the
+// first.
+func currentSequence(ctx context.Context, name string) (int64, error) {
+	var current counter
+	err := counterCollection.FindOne(ctx, bson.M{"_id": name}).Decode(&current)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	return current.Value, err
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func GetUserPreferences() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		preferences, err := findUserPreferences(ctx, c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if preferences == nil {
+			preferences = &models.UserPreferences{Diets: []string{}, Allergens: []string{}, DislikedIngredients: []string{}}
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched preferences for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": preferences}})
+	}
+}
+
+func UpdateUserPreferences() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireSelf(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var preferences models.UserPreferences
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&preferences); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if err := validatePreferences(&preferences); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+
+		log.Println("Saving preferences for user with ID ", c.Param("id"))
+		_, err := usersCollection.UpdateOne(ctx, bson.M{"_id": c.Param("id")}, bson.M{"$set": bson.M{"preferences": preferences}}, options.Update().SetUpsert(true))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully updated preferences for user with ID " + c.Param("id"), Data: map[string]interface{}{"data": preferences}})
+	}
+}
+
+func validatePreferences(preferences *models.UserPreferences) error {
+	if err := validateDietary(preferences.Diets, preferences.Allergens); err != nil {
+		return err
+	}
+	switch preferences.PreferredUnits {
+	case "", models.UnitsMetric, models.UnitsImperial:
+	default:
+		return errors.New("preferredUnits must be '" + models.UnitsMetric + "' or '" + models.UnitsImperial + "'")
+	}
+	if preferences.DefaultServings < 0 {
+		return errors.New("defaultServings cannot be negative")
+	}
+	if preferences.Diets == nil {
+		preferences.Diets = []string{}
+	}
+	if preferences.Allergens == nil {
+		preferences.Allergens = []string{}
+	}
+	disliked := []string{}
+	for _, ingredient := range preferences.DislikedIngredients {
+		if ingredient = strings.ToLower(strings.TrimSpace(ingredient)); ingredient != "" {
+			disliked = append(disliked, ingredient)
+		}
+	}
+	preferences.DislikedIngredients = disliked
+	return nil
+}
+
+// findUserPreferences returns nil preferences for users who never saved any.
+func findUserPreferences(ctx context.Context, userId string) (*models.UserPreferences, error) {
+	var mongoUser models.MongoUser
+	err := usersCollection.FindOne(ctx, bson.M{"_id": userId}).Decode(&mongoUser)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mongoUser.Preferences, nil
+}
+
+// preferenceFilter returns the conditions that apply the calling user's
+// preferences to a listing, if they opted in. Anonymous callers, and anyone
+// passing ?applyPreferences=false, get no extra conditions.
+func preferenceFilter(ctx context.Context, c *gin.Context) ([]bson.M, error) {
+	if c.Query("applyPreferences") == "false" {
+		return nil, nil
+	}
+	return userPreferenceConditions(ctx, optionalUser(c))
+}
+
+// userPreferenceConditions is preferenceFilter for a user that's already
+// known, nil when anonymous.
+func userPreferenceConditions(ctx context.Context, keycloakUser *models.KeycloakUser) ([]bson.M, error) {
+	if keycloakUser == nil {
+		return nil, nil
+	}
+	preferences, err := findUserPreferences(ctx, keycloakUser.Sub)
+	if err != nil || preferences == nil || !preferences.ApplyToListings {
+		return nil, err
+	}
+	log.Println("Applying preferences of user with ID ", keycloakUser.Sub, " to listing...")
+	return preferenceConditions(preferences), nil
+}
+
+func preferenceConditions(preferences *models.UserPreferences) []bson.M {
+	conditions := dietaryFilter(preferences.Diets, preferences.Allergens)
+	for _, ingredient := range preferences.DislikedIngredients {
+		conditions = append(conditions, bson.M{"ingredients": bson.M{"$not": primitive.Regex{Pattern: `\b` + regexp.QuoteMeta(ingredient), Options: "i"}}})
+	}
+	return conditions
+}
@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/jobs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+	"github.com/hopk8412/table-recipes-api/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/exp/slices"
+)
+
+// Deliveries are logged for this long before they are cleaned up.
+const webhookDeliveryRetention = 30 * 24 * time.Hour
+
+var webhookCollection *mongo.Collection = configs.GetCollection(configs.DB, "webhooks")
+var webhookDeliveryCollection *mongo.Collection = configs.GetCollection(configs.DB, "webhookDeliveries")
+
+// EnsureWebhookIndexes creates the indexes used to find subscribers of an
+// event and page through a subscription's deliveries.
+func EnsureWebhookIndexes(ctx context.Context) error {
+	_, err := webhookCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "events", Value: 1}, {Key: "active", Value: 1}}})
+	if err != nil {
+		return err
+	}
+	_, err = webhookDeliveryCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "subscriptionId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(webhookDeliveryRetention.Seconds()))},
+	})
+	return err
+}
+
+// PostWebhook subscribes a URL to events. The response is the only time the
+// signing secret is shown.
+func PostWebhook() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keycloakUser, ok := requireAdmin(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var webhookRequest models.WebhookSubscriptionRequest
+		defer cancel()
+
+		//validate request body
+		if err := c.BindJSON(&webhookRequest); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		now := time.Now().UTC()
+		subscription := models.WebhookSubscription{
+			Id:        primitive.NewObjectID().Hex(),
+			Active:    true,
+			CreatedBy: keycloakUser.Sub,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := applyWebhookRequest(&subscription, webhookRequest); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		subscription.Secret = secret
+
+		if _, err = webhookCollection.InsertOne(ctx, subscription); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		log.Println("Subscribed ", subscription.Url, " to ", strings.Join(subscription.Events, ", "))
+		c.JSON(http.StatusCreated, responses.RecipeResponse{Status: http.StatusCreated, Message: "Successfully created webhook!", Data: map[string]interface{}{"data": subscription}})
+	}
+}
+
+func GetWebhooks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		subscriptions := []models.WebhookSubscription{}
+		defer cancel()
+
+		results, err := webhookCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"createdAt": 1}).SetProjection(bson.M{"secret": 0}))
+		if err == nil {
+			err = results.All(ctx, &subscriptions)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched all webhooks!", Data: map[string]interface{}{"data": subscriptions}})
+	}
+}
+
+func GetWebhookById() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		subscription, ok := findWebhook(ctx, c)
+		if !ok {
+			return
+		}
+		subscription.Secret = ""
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched webhook with ID " + subscription.Id, Data: map[string]interface{}{"data": subscription}})
+	}
+}
+
+// UpdateWebhookById replaces a subscription's URL, events and description,
+// and pauses or resumes it. The secret doesn't change.
+func UpdateWebhookById() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var webhookRequest models.WebhookSubscriptionRequest
+		defer cancel()
+
+		subscription, ok := findWebhook(ctx, c)
+		if !ok {
+			return
+		}
+		//validate request body
+		if err := c.BindJSON(&webhookRequest); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if err := applyWebhookRequest(&subscription, webhookRequest); err != nil {
+			c.JSON(http.StatusBadRequest, responses.RecipeResponse{Status: http.StatusBadRequest, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		subscription.UpdatedAt = time.Now().UTC()
+
+		update := bson.M{"$set": bson.M{"url": subscription.Url, "events": subscription.Events, "description": subscription.Description, "active": subscription.Active, "updatedAt": subscription.UpdatedAt}}
+		if _, err := webhookCollection.UpdateByID(ctx, subscription.Id, update); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		subscription.Secret = ""
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully updated webhook with ID " + subscription.Id, Data: map[string]interface{}{"data": subscription}})
+	}
+}
+
+// DeleteWebhookById removes a subscription and its delivery log.
+func DeleteWebhookById() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		result, err := webhookCollection.DeleteOne(ctx, bson.M{"_id": c.Param("id")})
+		if err == nil {
+			_, err = webhookDeliveryCollection.DeleteMany(ctx, bson.M{"subscriptionId": c.Param("id")})
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully deleted webhook with ID " + c.Param("id"), Data: map[string]interface{}{"data": result}})
+	}
+}
+
+// GetWebhookDeliveries lists a subscription's deliveries, newest first.
+// ?status= narrows it down to pending, succeeded or failed ones.
+func GetWebhookDeliveries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		deliveries := []models.WebhookDelivery{}
+		defer cancel()
+
+		subscription, ok := findWebhook(ctx, c)
+		if !ok {
+			return
+		}
+		filter := bson.M{"subscriptionId": subscription.Id}
+		if status := c.Query("status"); status != "" {
+			filter["status"] = status
+		}
+		results, err := webhookDeliveryCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(100))
+		if err == nil {
+			err = results.All(ctx, &deliveries)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "Successfully fetched deliveries of webhook with ID " + subscription.Id, Data: map[string]interface{}{"data": deliveries}})
+	}
+}
+
+// RedeliverWebhook sends a logged delivery again, with a fresh set of
+// retries, whether or not it went through before.
+func RedeliverWebhook() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireAdmin(c); !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var delivery models.WebhookDelivery
+		defer cancel()
+
+		subscription, ok := findWebhook(ctx, c)
+		if !ok {
+			return
+		}
+		err := webhookDeliveryCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": c.Param("deliveryId"), "subscriptionId": subscription.Id},
+			bson.M{"$set": bson.M{"status": models.DeliveryPending}, "$unset": bson.M{"deliveredAt": ""}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&delivery)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no delivery with ID " + c.Param("deliveryId")}})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		if err = queueWebhookDelivery(ctx, delivery.Id); err != nil {
+			c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+			return
+		}
+		log.Println("Redelivering ", delivery.Event.Type, " delivery with ID ", delivery.Id)
+		c.JSON(http.StatusAccepted, responses.RecipeResponse{Status: http.StatusAccepted, Message: "Successfully queued redelivery of delivery with ID " + delivery.Id, Data: map[string]interface{}{"data": delivery}})
+	}
+}
+
+// findWebhook loads the subscription in the path, writing a 404 when there
+// isn't one.
+func findWebhook(ctx context.Context, c *gin.Context) (models.WebhookSubscription, bool) {
+	var subscription models.WebhookSubscription
+	err := webhookCollection.FindOne(ctx, bson.M{"_id": c.Param("id")}).Decode(&subscription)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, responses.RecipeResponse{Status: http.StatusNotFound, Message: "error", Data: map[string]interface{}{"data": "no webhook with ID " + c.Param("id")}})
+		return subscription, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.RecipeResponse{Status: http.StatusInternalServerError, Message: "error", Data: map[string]interface{}{"data": err.Error()}})
+		return subscription, false
+	}
+	return subscription, true
+}
+
+func applyWebhookRequest(subscription *models.WebhookSubscription, webhookRequest models.WebhookSubscriptionRequest) error {
+	webhookUrl, err := url.Parse(strings.TrimSpace(webhookRequest.Url))
+	if err != nil || (webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https") || webhookUrl.Host == "" {
+		return errors.New("url must be an http or https URL")
+	}
+	if len(webhookRequest.Events) == 0 {
+		return errors.New("events must list at least one of " + strings.Join(models.WebhookEvents, ", "))
+	}
+	events := []string{}
+	for _, event := range webhookRequest.Events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			return errors.New("events must be from " + strings.Join(models.WebhookEvents, ", ") + ", got '" + event + "'")
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	subscription.Url, subscription.Events = webhookUrl.String(), events
+	subscription.Description = strings.TrimSpace(webhookRequest.Description)
+	if webhookRequest.Active != nil {
+		subscription.Active = *webhookRequest.Active
+	}
+	return nil
+}
+
+// publishWebhookEvent logs a delivery of the event for every active
+// subscription to it and queues them to be sent. Webhooks are a side effect
+// of the request, so failures are logged rather than returned.
+func publishWebhookEvent(ctx context.Context, eventType string, data map[string]interface{}) {
+	var subscriptions []models.WebhookSubscription
+	results, err := webhookCollection.Find(ctx, bson.M{"events": eventType, "active": true}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err == nil {
+		err = results.All(ctx, &subscriptions)
+	}
+	if err != nil {
+		log.Println("Error finding subscribers of ", eventType, ": ", err)
+		return
+	}
+	event := models.WebhookEvent{Id: primitive.NewObjectID().Hex(), Type: eventType, CreatedAt: time.Now().UTC()}
+	// Round trip the data through JSON so it's logged, and sent, with the
+	// API's field names rather than the database's.
+	raw, err := json.Marshal(data)
+	if err == nil {
+		err = json.Unmarshal(raw, &event.Data)
+	}
+	if err != nil {
+		log.Println("Error encoding ", eventType, " for webhooks: ", err)
+		return
+	}
+	for _, subscription := range subscriptions {
+		delivery := models.WebhookDelivery{
+			Id:             primitive.NewObjectID().Hex(),
+			SubscriptionId: subscription.Id,
+			Event:          event,
+			Status:         models.DeliveryPending,
+			Attempts:       []models.WebhookAttempt{},
+			CreatedAt:      event.CreatedAt,
+		}
+		if _, err = webhookDeliveryCollection.InsertOne(ctx, delivery); err == nil {
+			err = queueWebhookDelivery(ctx, delivery.Id)
+		}
+		if err != nil {
+			log.Println("Error queueing ", eventType, " for webhook with ID ", subscription.Id, ": ", err)
+		}
+	}
+}
+
+func queueWebhookDelivery(ctx context.Context, deliveryId string) error {
+	_, err := jobs.EnqueueWithAttempts(ctx, JobTypeWebhookDelivery, "", map[string]interface{}{"deliveryId": deliveryId}, webhookMaxAttempts)
+	return err
+}
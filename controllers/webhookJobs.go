@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/jobs"
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/webhooks"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const JobTypeWebhookDelivery = "webhooks.deliver"
+
+// With the queue's backoff this keeps trying for about ten minutes.
+const webhookMaxAttempts = 8
+
+// RegisterWebhookJobs lets the job queue send webhook deliveries and retry
+// the ones that fail.
+func RegisterWebhookJobs() {
+	jobs.RegisterInternal(JobTypeWebhookDelivery, deliverWebhookJob)
+}
+
+// deliverWebhookJob makes one attempt at the payload's "deliveryId" and logs
+// it. A failed attempt fails the job so the queue retries it, until the last
+// attempt marks the delivery failed for good.
+func deliverWebhookJob(ctx context.Context, job *models.Job, progress jobs.ProgressFunc) (map[string]interface{}, error) {
+	deliveryId, _ := job.Payload["deliveryId"].(string)
+	var delivery models.WebhookDelivery
+	if err := webhookDeliveryCollection.FindOne(ctx, bson.M{"_id": deliveryId}).Decode(&delivery); err != nil {
+		return nil, err
+	}
+	var subscription models.WebhookSubscription
+	err := webhookCollection.FindOne(ctx, bson.M{"_id": delivery.SubscriptionId}).Decode(&subscription)
+	if err == mongo.ErrNoDocuments || (err == nil && !subscription.Active) {
+		// Nothing to retry for a subscription that was removed or paused
+		_, err = webhookDeliveryCollection.UpdateByID(ctx, delivery.Id, bson.M{"$set": bson.M{"status": models.DeliveryFailed}})
+		return map[string]interface{}{"deliveryId": delivery.Id, "status": models.DeliveryFailed}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := webhooks.Send(ctx, subscription, delivery)
+	set := bson.M{}
+	switch {
+	case attempt.Error == "":
+		set["status"], set["deliveredAt"] = models.DeliverySucceeded, time.Now().UTC()
+	case job.Attempts >= job.MaxAttempts:
+		set["status"] = models.DeliveryFailed
+	}
+	update := bson.M{"$push": bson.M{"attempts": attempt}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if _, err = webhookDeliveryCollection.UpdateByID(ctx, delivery.Id, update); err != nil {
+		return nil, err
+	}
+	if attempt.Error != "" {
+		return nil, errors.New(attempt.Error)
+	}
+	return map[string]interface{}{"deliveryId": delivery.Id, "status": models.DeliverySucceeded, "statusCode": attempt.StatusCode}, nil
+}
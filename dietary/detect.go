@@ -0,0 +1,155 @@
+package dietary
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dictionary.json is the maintained list of keywords for each ingredient
+// category, and which categories each diet rules out. Exceptions are
+// phrases that contain a keyword without belonging to the category, like
+// "peanut butter" for dairy.
+//
+//go:embed data/dictionary.json
+var dictionaryData []byte
+
+type category struct {
+	Allergen   bool     `json:"allergen"`
+	Keywords   []string `json:"keywords"`
+	Exceptions []string `json:"exceptions"`
+
+	keywordPattern   *regexp.Regexp
+	exceptionPattern *regexp.Regexp
+}
+
+var dictionary struct {
+	Categories map[string]*category `json:"categories"`
+	Diets      map[string][]string  `json:"diets"`
+}
+
+func init() {
+	if err := json.Unmarshal(dictionaryData, &dictionary); err != nil {
+		log.Fatal("invalid dietary dictionary: ", err)
+	}
+	for _, cat := range dictionary.Categories {
+		cat.keywordPattern = wordsPattern(cat.Keywords)
+		cat.exceptionPattern = wordsPattern(cat.Exceptions)
+	}
+}
+
+// wordsPattern builds a single regexp matching any of the given phrases as
+// whole words, or nil when there are none.
+func wordsPattern(phrases []string) *regexp.Regexp {
+	if len(phrases) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(phrases))
+	for i, phrase := range phrases {
+		quoted[i] = regexp.QuoteMeta(strings.ToLower(phrase))
+	}
+	return regexp.MustCompile(`\b(` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// Tags lists every dietary tag recipes can carry, e.g. "vegan".
+func Tags() []string {
+	tags := make([]string, 0, len(dictionary.Diets))
+	for tag := range dictionary.Diets {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Allergens lists every allergen that can be detected, e.g. "peanut".
+func Allergens() []string {
+	var allergens []string
+	for name, cat := range dictionary.Categories {
+		if cat.Allergen {
+			allergens = append(allergens, name)
+		}
+	}
+	sort.Strings(allergens)
+	return allergens
+}
+
+func IsTag(tag string) bool {
+	_, ok := dictionary.Diets[tag]
+	return ok
+}
+
+func IsAllergen(allergen string) bool {
+	cat, ok := dictionary.Categories[allergen]
+	return ok && cat.Allergen
+}
+
+// Detect works out from the ingredient lines which allergens a recipe
+// contains and which dietary tags it qualifies for.
+func Detect(ingredientLines []string) (tags []string, allergens []string) {
+	if len(ingredientLines) == 0 {
+		return []string{}, []string{}
+	}
+	found := detectCategories(ingredientLines)
+
+	tags = []string{}
+	allergens = []string{}
+	for name := range found {
+		if dictionary.Categories[name].Allergen {
+			allergens = append(allergens, name)
+		}
+	}
+	for tag := range dictionary.Diets {
+		if !ruledOut(tag, found) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	sort.Strings(allergens)
+	return tags, allergens
+}
+
+// Consistent drops the tags an author gave a recipe that its ingredients
+// rule out, so a "vegan" tag can't hide the milk in it.
+func Consistent(tags []string, ingredientLines []string) []string {
+	found := detectCategories(ingredientLines)
+	var consistent []string
+	for _, tag := range tags {
+		if !ruledOut(tag, found) {
+			consistent = append(consistent, tag)
+		}
+	}
+	return consistent
+}
+
+func ruledOut(tag string, found map[string]bool) bool {
+	for _, name := range dictionary.Diets[tag] {
+		if found[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// detectCategories finds the ingredient categories mentioned in the lines.
+func detectCategories(ingredientLines []string) map[string]bool {
+	found := map[string]bool{}
+	for _, line := range ingredientLines {
+		text := strings.ToLower(line)
+		for name, cat := range dictionary.Categories {
+			if found[name] || cat.keywordPattern == nil {
+				continue
+			}
+			candidate := text
+			if cat.exceptionPattern != nil {
+				candidate = cat.exceptionPattern.ReplaceAllString(candidate, " ")
+			}
+			if cat.keywordPattern.MatchString(candidate) {
+				found[name] = true
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,85 @@
+package dietary
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		tags      []string
+		allergens []string
+	}{
+		{
+			name:      "no ingredients",
+			lines:     nil,
+			tags:      []string{},
+			allergens: []string{},
+		},
+		{
+			name:      "vegan",
+			lines:     []string{"1 cup rice", "2 carrots"},
+			tags:      []string{"dairy-free", "egg-free", "gluten-free", "nut-free", "pescatarian", "soy-free", "vegan", "vegetarian"},
+			allergens: []string{},
+		},
+		{
+			name:      "exceptions don't count",
+			lines:     []string{"1 cup almond milk", "1 eggplant", "1 cup rice flour", "a pinch of nutmeg"},
+			tags:      []string{"dairy-free", "egg-free", "gluten-free", "pescatarian", "soy-free", "vegan", "vegetarian"},
+			allergens: []string{"tree-nut"},
+		},
+		{
+			name:      "nuts without a kind",
+			lines:     []string{"1 cup mixed nuts", "2 tbsp basil pesto"},
+			tags:      []string{"dairy-free", "egg-free", "gluten-free", "pescatarian", "soy-free", "vegan", "vegetarian"},
+			allergens: []string{"tree-nut"},
+		},
+		{
+			name:      "not nuts",
+			lines:     []string{"1 butternut squash", "1 cup coconut milk", "1 tsp nutmeg", "nut-free granola"},
+			tags:      []string{"dairy-free", "egg-free", "gluten-free", "nut-free", "pescatarian", "soy-free", "vegan", "vegetarian"},
+			allergens: []string{},
+		},
+		{
+			name:      "meat and dairy",
+			lines:     []string{"2 chicken breasts", "1/2 cup butter", "2 eggs"},
+			tags:      []string{"gluten-free", "nut-free", "soy-free"},
+			allergens: []string{"dairy", "egg"},
+		},
+		{
+			name:      "soy sauce has wheat in it",
+			lines:     []string{"1 lb Salmon fillet", "1 tbsp soy sauce"},
+			tags:      []string{"dairy-free", "egg-free", "nut-free", "pescatarian"},
+			allergens: []string{"fish", "gluten", "soy"},
+		},
+	}
+	for _, test := range tests {
+		tags, allergens := Detect(test.lines)
+		if !reflect.DeepEqual(tags, test.tags) {
+			t.Errorf("%s: Detect tags = %v, want %v", test.name, tags, test.tags)
+		}
+		if !reflect.DeepEqual(allergens, test.allergens) {
+			t.Errorf("%s: Detect allergens = %v, want %v", test.name, allergens, test.allergens)
+		}
+	}
+}
+
+func TestConsistent(t *testing.T) {
+	tests := []struct {
+		tags  []string
+		lines []string
+		want  []string
+	}{
+		{[]string{"vegan", "gluten-free"}, []string{"1 cup milk", "1 cup rice"}, []string{"gluten-free"}},
+		{[]string{"vegetarian"}, []string{"1 cup oat milk", "2 eggs"}, []string{"vegetarian"}},
+		{[]string{"nut-free"}, []string{"2 tbsp peanut butter"}, nil},
+		{nil, []string{"1 cup milk"}, nil},
+	}
+	for _, test := range tests {
+		if got := Consistent(test.tags, test.lines); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Consistent(%v, %v) = %v, want %v", test.tags, test.lines, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package durations
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Match is a duration mentioned in free text. Ranges like "20-25 minutes"
+// have Min and Max set to either end, otherwise they're equal.
+type Match struct {
+	Text string
+	Min  time.Duration
+	Max  time.Duration
+}
+
+const amount = `(\d+(?:\.\d+)?(?:\s+\d+/\d+)?|\d+/\d+|an?|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve|fifteen|twenty|thirty|forty|forty-five|sixty)`
+
+var mentionPattern = regexp.MustCompile(`(?i)\b` + amount + `(?:\s*(?:-|–|\s+to\s+|\s+or\s+)\s*` + amount + `)?[\s-]*(hours?|hrs?|minutes?|mins?|seconds?|secs?)\b`)
+
+// joinPattern is what can sit between the parts of "1 hour and 15 minutes".
+var joinPattern = regexp.MustCompile(`(?i)^\s*(?:,|and|,\s*and)?\s*$`)
+
+var words = map[string]float64{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	"fifteen": 15, "twenty": 20, "thirty": 30, "forty": 40, "forty-five": 45, "sixty": 60,
+}
+
+// Find returns the durations mentioned in text, such as "bake 25 minutes" or
+// "simmer for 1 hour and 15 minutes", in the order they appear.
+func Find(text string) []Match {
+	var found []Match
+	var lastUnit time.Duration
+	var lastEnd int
+	for _, loc := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		unit := unitOf(text[loc[6]:loc[7]])
+		low := parseAmount(text[loc[2]:loc[3]])
+		high := low
+		if loc[4] >= 0 {
+			high = parseAmount(text[loc[4]:loc[5]])
+		}
+		if low <= 0 || high < low {
+			continue
+		}
+		match := Match{
+			Text: text[loc[0]:loc[1]],
+			Min:  time.Duration(low * float64(unit)),
+			Max:  time.Duration(high * float64(unit)),
+		}
+		// Fold "1 hour" followed by "15 minutes" into a single mention
+		if n := len(found); n > 0 && unit < lastUnit && joinPattern.MatchString(text[lastEnd:loc[0]]) {
+			previous := &found[n-1]
+			previous.Text = strings.TrimSpace(previous.Text + text[lastEnd:loc[1]])
+			previous.Min += match.Min
+			previous.Max += match.Max
+		} else {
+			found = append(found, match)
+		}
+		lastUnit, lastEnd = unit, loc[1]
+	}
+	return found
+}
+
+func unitOf(word string) time.Duration {
+	switch strings.ToLower(word)[0] {
+	case 'h':
+		return time.Hour
+	case 'm':
+		return time.Minute
+	default:
+		return time.Second
+	}
+}
+
+func parseAmount(value string) float64 {
+	value = strings.ToLower(value)
+	if n, ok := words[value]; ok {
+		return n
+	}
+	var total float64
+	for _, part := range strings.Fields(value) {
+		if numerator, denominator, ok := strings.Cut(part, "/"); ok {
+			n, err1 := strconv.ParseFloat(numerator, 64)
+			d, err2 := strconv.ParseFloat(denominator, 64)
+			if err1 != nil || err2 != nil || d == 0 {
+				return 0
+			}
+			total += n / d
+			continue
+		}
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0
+		}
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,38 @@
+package durations
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Match
+	}{
+		{"Bake for 25 minutes.", []Match{{"25 minutes", 25 * time.Minute, 25 * time.Minute}}},
+		{"Simmer 20-25 mins", []Match{{"20-25 mins", 20 * time.Minute, 25 * time.Minute}}},
+		{"Roast for 1 hour and 15 minutes", []Match{{"1 hour and 15 minutes", 75 * time.Minute, 75 * time.Minute}}},
+		{"Rest for an hour", []Match{{"an hour", time.Hour, time.Hour}}},
+		{"Cook 1 1/2 hours", []Match{{"1 1/2 hours", 90 * time.Minute, 90 * time.Minute}}},
+		{"Stir for thirty seconds, then boil 10 minutes", []Match{
+			{"thirty seconds", 30 * time.Second, 30 * time.Second},
+			{"10 minutes", 10 * time.Minute, 10 * time.Minute},
+		}},
+		{"Whisk 5 to 10 minutes", []Match{{"5 to 10 minutes", 5 * time.Minute, 10 * time.Minute}}},
+		{"Season to taste", nil},
+		{"Boil 10-5 minutes", nil},
+	}
+	for _, test := range tests {
+		got := Find(test.text)
+		if len(got) != len(test.want) {
+			t.Errorf("Find(%q) = %v, want %v", test.text, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("Find(%q)[%d] = %+v, want %+v", test.text, i, got[i], test.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package durations
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid ISO 8601 duration")
+
+// Only day and time components make sense for recipes, so years, months and
+// weeks aren't supported.
+var pattern = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// Parse parses an ISO 8601 duration such as "PT1H30M" or "P1DT2H".
+func Parse(value string) (time.Duration, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(value))
+	match := pattern.FindStringSubmatch(normalized)
+	// The pattern allows every component to be missing, but a duration
+	// needs at least one, and a T needs a time after it
+	if match == nil || normalized == "P" || strings.HasSuffix(normalized, "T") {
+		return 0, ErrInvalid
+	}
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var total time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, ErrInvalid
+		}
+		total += time.Duration(amount * float64(unit))
+	}
+	return total, nil
+}
+
+// Format writes d as an ISO 8601 duration, e.g. 90 minutes is "PT1H30M".
+func Format(d time.Duration) string {
+	if d <= 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	b.WriteString("P")
+	if days := d / (24 * time.Hour); days > 0 {
+		b.WriteString(strconv.Itoa(int(days)) + "D")
+		d -= days * 24 * time.Hour
+	}
+	if d > 0 {
+		b.WriteString("T")
+		if hours := d / time.Hour; hours > 0 {
+			b.WriteString(strconv.Itoa(int(hours)) + "H")
+			d -= hours * time.Hour
+		}
+		if minutes := d / time.Minute; minutes > 0 {
+			b.WriteString(strconv.Itoa(int(minutes)) + "M")
+			d -= minutes * time.Minute
+		}
+		if seconds := d / time.Second; seconds > 0 {
+			b.WriteString(strconv.Itoa(int(seconds)) + "S")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package durations
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"PT30M", 30 * time.Minute},
+		{"PT1H30M", 90 * time.Minute},
+		{"P1DT2H", 26 * time.Hour},
+		{"P2D", 48 * time.Hour},
+		{"PT45S", 45 * time.Second},
+		{"PT1.5H", 90 * time.Minute},
+		{"pt20m", 20 * time.Minute},
+		{" PT1H ", time.Hour},
+		{"PT0S", 0},
+	}
+	for _, test := range tests {
+		got, err := Parse(test.value)
+		if err != nil {
+			t.Errorf("Parse(%q) returned %v", test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Parse(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	for _, value := range []string{"", " ", "P", "p", " P", "PT", "pt ", "P1DT", "1H", "PT1H30", "P1W", "P1Y", "PT-5M", "30 minutes"} {
+		if got, err := Parse(value); err != ErrInvalid {
+			t.Errorf("Parse(%q) = %v, %v, want ErrInvalid", value, got, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{0, "PT0S"},
+		{-time.Minute, "PT0S"},
+		{25 * time.Minute, "PT25M"},
+		{90 * time.Minute, "PT1H30M"},
+		{26 * time.Hour, "P1DT2H"},
+		{48 * time.Hour, "P2D"},
+		{time.Hour + 5*time.Second, "PT1H5S"},
+	}
+	for _, test := range tests {
+		if got := Format(test.duration); got != test.want {
+			t.Errorf("Format(%v) = %q, want %q", test.duration, got, test.want)
+		}
+		if parsed, err := Parse(Format(test.duration)); err != nil || (test.duration > 0 && parsed != test.duration) {
+			t.Errorf("Parse(Format(%v)) = %v, %v", test.duration, parsed, err)
+		}
+	}
+}
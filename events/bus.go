@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	RecipeCreated = "recipe.created"
+	RecipeUpdated = "recipe.updated"
+	RecipeDeleted = "recipe.deleted"
+	UserCreated   = "user.created"
+	UserUpdated   = "user.updated"
+	UserDeleted   = "user.deleted"
+)
+
+// Event is a change to a recipe or user, whichever way it was made. Id is
+// unique per change, so consumers that see an event twice can tell.
+type Event struct {
+	Id         string    `json:"id"`
+	Type       string    `json:"type"`
+	EntityId   string    `json:"entityId"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// ChangedFields lists the top level fields an update touched.
+	ChangedFields []string               `json:"changedFields,omitempty"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+}
+
+// Handler reacts to an event. Errors are logged by the bus, events aren't
+// handed out again.
+type Handler func(ctx context.Context, event Event) error
+
+// Bus carries events from whoever noticed a change to whoever cares about
+// it.
+type Bus interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe calls handler with every event whose type matches pattern:
+	// an exact type like "recipe.created", every event of an entity with
+	// "recipe.*", or everything with "*". Only one of the subscriptions
+	// sharing a name gets each event, so running several copies of the API
+	// handles every event once.
+	Subscribe(name string, pattern string, handler Handler) error
+	// Close stops delivering events once the ones already published have
+	// been handled.
+	Close() error
+}
+
+var ErrClosed = errors.New("event bus is closed")
+
+// Matches reports whether an event type matches a Subscribe pattern.
+func Matches(pattern string, eventType string) bool {
+	if pattern == "*" || pattern == eventType {
+		return true
+	}
+	return strings.HasSuffix(pattern, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*"))
+}
@@ -0,0 +1,25 @@
+package events
+
+import "testing"
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		eventType string
+		want      bool
+	}{
+		{pattern: "*", eventType: RecipeCreated, want: true},
+		{pattern: RecipeUpdated, eventType: RecipeUpdated, want: true},
+		{pattern: RecipeUpdated, eventType: RecipeCreated, want: false},
+		{pattern: "recipe.*", eventType: RecipeDeleted, want: true},
+		{pattern: "recipe.*", eventType: UserUpdated, want: false},
+		{pattern: "recipe.*", eventType: "recipes.created", want: false},
+		{pattern: "recipe*", eventType: RecipeCreated, want: false},
+		{pattern: "user.*", eventType: "user", want: false},
+	}
+	for _, test := range tests {
+		if got := Matches(test.pattern, test.eventType); got != test.want {
+			t.Errorf("Matches(%q, %q) = %v, want %v", test.pattern, test.eventType, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Only one copy of the API watches each collection at a time, holding a
+// lease it renews this often. If it dies, another takes over once the lease
+// runs out and resumes where it left off.
+const leaseRenewInterval = 10 * time.Second
+const leaseTimeout = 30 * time.Second
+
+const retryInterval = 5 * time.Second
+
+// streamsCollectionName is the collection, next to the watched ones, that
+// keeps each watched collection's lease and resume token.
+const streamsCollectionName = "eventStreams"
+
+// watcherId tells this process's leases apart from other copies of the API.
+var watcherId = fmt.Sprintf("%s-%d-%s", hostname(), os.Getpid(), primitive.NewObjectID().Hex())
+
+// Source is a collection to turn into events. Entity names the events, so
+// inserts into a "recipe" source become recipe.created. Decode turns a full
+// document into what goes in the event's Data under Entity.
+type Source struct {
+	Collection *mongo.Collection
+	Entity     string
+	Decode     func(document bson.Raw) (interface{}, error)
+}
+
+type changeEvent struct {
+	OperationType     string              `bson:"operationType"`
+	DocumentKey       bson.Raw            `bson:"documentKey"`
+	FullDocument      bson.Raw            `bson:"fullDocument"`
+	WallTime          *time.Time          `bson:"wallTime"`
+	ClusterTime       primitive.Timestamp `bson:"clusterTime"`
+	UpdateDescription struct {
+		UpdatedFields bson.Raw `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+}
+
+type streamState struct {
+	ResumeToken bson.Raw `bson:"resumeToken,omitempty"`
+}
+
+// Watch publishes every insert, update, replace and delete in the source's
+// collection to the bus until ctx is cancelled. Change streams need MongoDB
+// to run as a replica set; failures are logged and retried.
+func Watch(ctx context.Context, bus Bus, source Source) {
+	name := source.Collection.Name()
+	streams := source.Collection.Database().Collection(streamsCollectionName)
+	for {
+		state, leader, err := acquireLease(ctx, streams, name)
+		if err != nil && ctx.Err() == nil {
+			log.Println("Error acquiring change stream lease for ", name, ": ", err)
+		}
+		if leader {
+			log.Println("Watching ", name, " for changes...")
+			if err = watch(ctx, bus, source, streams, state); err != nil && ctx.Err() == nil {
+				log.Println("Error watching ", name, " for changes: ", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			releaseLease(streams, name)
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+func watch(ctx context.Context, bus Bus, source Source, streams *mongo.Collection, state streamState) error {
+	name := source.Collection.Name()
+	streamOptions := options.ChangeStream().SetFullDocument(options.UpdateLookup).SetMaxAwaitTime(2 * time.Second)
+	if state.ResumeToken != nil {
+		streamOptions.SetResumeAfter(state.ResumeToken)
+	}
+	stream, err := source.Collection.Watch(ctx, mongo.Pipeline{}, streamOptions)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	renewAt := time.Now().Add(leaseRenewInterval)
+	for {
+		for stream.TryNext(ctx) {
+			var change changeEvent
+			if err = stream.Decode(&change); err != nil {
+				return err
+			}
+			event, ok, err := normalize(source, change, stream.ResumeToken())
+			if err != nil {
+				return err
+			}
+			if ok {
+				if err = bus.Publish(ctx, event); err != nil {
+					return err
+				}
+			}
+			if err = saveResumeToken(ctx, streams, name, stream.ResumeToken()); err != nil {
+				return err
+			}
+			renewAt = time.Now().Add(leaseRenewInterval)
+		}
+		if err = stream.Err(); err != nil {
+			return err
+		}
+		if time.Now().After(renewAt) {
+			// Quiet collections still hold on to the lease
+			if err = saveResumeToken(ctx, streams, name, stream.ResumeToken()); err != nil {
+				return err
+			}
+			renewAt = time.Now().Add(leaseRenewInterval)
+		}
+	}
+}
+
+// normalize turns a raw change into an event. Changes that aren't about a
+// single document, like the collection being dropped, are skipped.
+func normalize(source Source, change changeEvent, resumeToken bson.Raw) (Event, bool, error) {
+	event := Event{Data: map[string]interface{}{}}
+	switch change.OperationType {
+	case "insert":
+		event.Type = source.Entity + ".created"
+	case "update", "replace":
+		event.Type = source.Entity + ".updated"
+	case "delete":
+		event.Type = source.Entity + ".deleted"
+	default:
+		return event, false, nil
+	}
+	if data, ok := resumeToken.Lookup("_data").StringValueOK(); ok {
+		event.Id = data
+	} else {
+		event.Id = resumeToken.String()
+	}
+	id := change.DocumentKey.Lookup("_id")
+	if entityId, ok := id.StringValueOK(); ok {
+		event.EntityId = entityId
+	} else {
+		event.EntityId = id.String()
+	}
+	if change.WallTime != nil {
+		event.OccurredAt = change.WallTime.UTC()
+	} else {
+		event.OccurredAt = time.Unix(int64(change.ClusterTime.T), 0).UTC()
+	}
+	event.ChangedFields = changedFields(change)
+
+	// Updated documents that were deleted before the lookup have no full
+	// document, deleted ones never do.
+	if change.FullDocument != nil {
+		document, err := source.Decode(change.FullDocument)
+		if err != nil {
+			return event, false, fmt.Errorf("decoding %s %s: %w", source.Entity, event.EntityId, err)
+		}
+		event.Data[source.Entity] = document
+	}
+	event.Data[source.Entity+"Id"] = event.EntityId
+	return event, true, nil
+}
+
+// changedFields lists the top level fields touched by an update, sorted.
+func changedFields(change changeEvent) []string {
+	seen := map[string]bool{}
+	if elements, err := change.UpdateDescription.UpdatedFields.Elements(); err == nil {
+		for _, element := range elements {
+			seen[strings.SplitN(element.Key(), ".", 2)[0]] = true
+		}
+	}
+	for _, field := range change.UpdateDescription.RemovedFields {
+		seen[strings.SplitN(field, ".", 2)[0]] = true
+	}
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// acquireLease takes the collection's lease if nobody else holds a live
+// one, and returns where the last holder stopped.
+func acquireLease(ctx context.Context, streams *mongo.Collection, name string) (streamState, bool, error) {
+	var state streamState
+	now := time.Now().UTC()
+	filter := bson.M{"_id": name, "$or": bson.A{
+		bson.M{"owner": watcherId},
+		bson.M{"owner": bson.M{"$exists": false}},
+		bson.M{"leaseUntil": bson.M{"$lt": now}},
+	}}
+	update := bson.M{"$set": bson.M{"owner": watcherId, "leaseUntil": now.Add(leaseTimeout)}}
+	err := streams.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&state)
+	if mongo.IsDuplicateKeyError(err) {
+		// Someone else holds the lease, the upsert collided with their document
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, err
+	}
+	return state, true, nil
+}
+
+// saveResumeToken records how far the stream got and renews the lease. It
+// fails once another watcher has taken the lease over.
+func saveResumeToken(ctx context.Context, streams *mongo.Collection, name string, resumeToken bson.Raw) error {
+	set := bson.M{"leaseUntil": time.Now().UTC().Add(leaseTimeout)}
+	if resumeToken != nil {
+		set["resumeToken"] = resumeToken
+	}
+	result, err := streams.UpdateOne(ctx, bson.M{"_id": name, "owner": watcherId}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("lost change stream lease for %s", name)
+	}
+	return nil
+}
+
+// releaseLease lets another copy of the API take over straight away.
+func releaseLease(streams *mongo.Collection, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := streams.UpdateOne(ctx, bson.M{"_id": name, "owner": watcherId}, bson.M{"$unset": bson.M{"owner": "", "leaseUntil": ""}})
+	if err != nil {
+		log.Println("Error releasing change stream lease for ", name, ": ", err)
+	}
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return name
+}
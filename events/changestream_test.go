@@ -0,0 +1,94 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mustMarshal(t *testing.T, document interface{}) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestChangedFields(t *testing.T) {
+	var change changeEvent
+	change.UpdateDescription.UpdatedFields = mustMarshal(t, bson.D{
+		{Key: "title", Value: "Soup"},
+		{Key: "ingredients.2", Value: "1 onion"},
+		{Key: "nutrition.calories", Value: 120},
+	})
+	change.UpdateDescription.RemovedFields = []string{"imageLinks", "nutrition.fat"}
+	want := []string{"imageLinks", "ingredients", "nutrition", "title"}
+	if got := changedFields(change); !reflect.DeepEqual(got, want) {
+		t.Errorf("changedFields = %v, want %v", got, want)
+	}
+	if got := changedFields(changeEvent{}); len(got) != 0 {
+		t.Errorf("changedFields of an insert = %v, want none", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	type decoded struct {
+		Id    string `bson:"_id"`
+		Title string `bson:"title"`
+	}
+	source := Source{Entity: "recipe", Decode: func(document bson.Raw) (interface{}, error) {
+		var recipe decoded
+		err := bson.Unmarshal(document, &recipe)
+		return recipe, err
+	}}
+	wallTime := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	resumeToken := mustMarshal(t, bson.M{"_data": "8264"})
+
+	update := changeEvent{
+		OperationType: "update",
+		DocumentKey:   mustMarshal(t, bson.M{"_id": "soup"}),
+		FullDocument:  mustMarshal(t, bson.M{"_id": "soup", "title": "Soup"}),
+		WallTime:      &wallTime,
+	}
+	update.UpdateDescription.UpdatedFields = mustMarshal(t, bson.M{"title": "Soup"})
+	event, ok, err := normalize(source, update, resumeToken)
+	if err != nil || !ok {
+		t.Fatalf("normalize(update) = %v, %v", ok, err)
+	}
+	want := Event{
+		Id:            "8264",
+		Type:          RecipeUpdated,
+		EntityId:      "soup",
+		OccurredAt:    wallTime,
+		ChangedFields: []string{"title"},
+		Data:          map[string]interface{}{"recipe": decoded{Id: "soup", Title: "Soup"}, "recipeId": "soup"},
+	}
+	if !reflect.DeepEqual(event, want) {
+		t.Errorf("normalize(update) = %+v, want %+v", event, want)
+	}
+
+	// Deletes have no full document and only the cluster time
+	objectId := primitive.NewObjectID()
+	event, ok, err = normalize(source, changeEvent{
+		OperationType: "delete",
+		DocumentKey:   mustMarshal(t, bson.M{"_id": objectId}),
+		ClusterTime:   primitive.Timestamp{T: uint32(wallTime.Unix())},
+	}, resumeToken)
+	if err != nil || !ok {
+		t.Fatalf("normalize(delete) = %v, %v", ok, err)
+	}
+	if event.Type != RecipeDeleted || event.EntityId == "" || !event.OccurredAt.Equal(wallTime) {
+		t.Errorf("normalize(delete) = %+v", event)
+	}
+	if _, found := event.Data["recipe"]; found {
+		t.Errorf("deleted event carries a recipe: %v", event.Data)
+	}
+
+	if _, ok, err = normalize(source, changeEvent{OperationType: "drop"}, resumeToken); ok || err != nil {
+		t.Errorf("normalize(drop) = %v, %v, want it skipped", ok, err)
+	}
+}
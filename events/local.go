@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// How many events a local subscriber can fall behind before Publish waits
+// for it.
+const localQueueSize = 256
+
+const handlerTimeout = 30 * time.Second
+
+type localSubscription struct {
+	name    string
+	pattern string
+	handler Handler
+	queue   chan Event
+}
+
+// LocalBus hands events to subscribers in the same process. Each
+// subscription gets its events in order on its own goroutine.
+type LocalBus struct {
+	mu            sync.RWMutex
+	subscriptions []*localSubscription
+	closed        bool
+	wg            sync.WaitGroup
+}
+
+func NewLocalBus() *LocalBus {
+	return &LocalBus{}
+}
+
+func (b *LocalBus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+	// Only one subscription per name gets the event, like a queue group
+	delivered := map[string]bool{}
+	for _, subscription := range b.subscriptions {
+		if delivered[subscription.name] || !Matches(subscription.pattern, event.Type) {
+			continue
+		}
+		delivered[subscription.name] = true
+		select {
+		case subscription.queue <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *LocalBus) Subscribe(name string, pattern string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	subscription := &localSubscription{name: name, pattern: pattern, handler: handler, queue: make(chan Event, localQueueSize)}
+	b.subscriptions = append(b.subscriptions, subscription)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for event := range subscription.queue {
+			handle(subscription.name, subscription.handler, event)
+		}
+	}()
+	return nil
+}
+
+func (b *LocalBus) Close() error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, subscription := range b.subscriptions {
+			close(subscription.queue)
+		}
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+	return nil
+}
+
+func handle(name string, handler Handler, event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), handlerTimeout)
+	defer cancel()
+	if err := handler(ctx, event); err != nil {
+		log.Println("Error handling ", event.Type, " event ", event.Id, " in ", name, ": ", err)
+	}
+}
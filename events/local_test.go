@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// Subscriptions sharing a name split the events between them, like copies
+// of the API in a NATS queue group, while every name sees each event.
+func TestLocalBusQueueGroups(t *testing.T) {
+	bus := NewLocalBus()
+	var mu sync.Mutex
+	received := map[string][]string{}
+	subscribe := func(name string, pattern string) {
+		err := bus.Subscribe(name, pattern, func(ctx context.Context, event Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received[name] = append(received[name], event.Id)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	subscribe("webhooks", "recipe.*")
+	subscribe("webhooks", "recipe.*")
+	subscribe("live", RecipeUpdated)
+	subscribe("users", "user.*")
+
+	for _, event := range []Event{{Id: "1", Type: RecipeCreated}, {Id: "2", Type: RecipeUpdated}, {Id: "3", Type: RecipeUpdated}} {
+		if err := bus.Publish(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(received["webhooks"]); got != 3 {
+		t.Errorf("webhooks got %d events, want each of the 3 once", got)
+	}
+	if got := received["live"]; len(got) != 2 || got[0] != "2" || got[1] != "3" {
+		t.Errorf("live got %v, want [2 3] in order", got)
+	}
+	if got := received["users"]; len(got) != 0 {
+		t.Errorf("users got %v, want nothing", got)
+	}
+	if err := bus.Publish(context.Background(), Event{Id: "4", Type: RecipeCreated}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish after Close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSBus publishes events to a NATS server as JSON, on subjects made of
+// the prefix and the event type, e.g. "tablerecipes.recipe.created". Other
+// services can subscribe to the same subjects.
+type NATSBus struct {
+	conn   *nats.Conn
+	prefix string
+	closed chan struct{}
+}
+
+func NewNATSBus(url string, prefix string) (*NATSBus, error) {
+	closed := make(chan struct{})
+	conn, err := nats.Connect(url,
+		nats.Name("table-recipes-api"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Println("Disconnected from NATS: ", err)
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			log.Println("Reconnected to NATS at ", conn.ConnectedUrl())
+		}),
+		nats.ClosedHandler(func(*nats.Conn) {
+			close(closed)
+		}))
+	if err != nil {
+		return nil, err
+	}
+	return &NATSBus{conn: conn, prefix: prefix, closed: closed}, nil
+}
+
+func (b *NATSBus) Publish(ctx context.Context, event Event) error {
+	if b.conn.IsClosed() || b.conn.IsDraining() {
+		return ErrClosed
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.conn.Publish(b.subject(event.Type), data)
+}
+
+// Subscribe joins a queue group named after the subscription, so NATS
+// gives each event to just one copy of the API.
+func (b *NATSBus) Subscribe(name string, pattern string, handler Handler) error {
+	subject := b.subject(pattern)
+	if pattern == "*" {
+		subject = b.subject(">")
+	}
+	_, err := b.conn.QueueSubscribe(subject, name, func(msg *nats.Msg) {
+		var event Event
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			log.Println("Error decoding event on ", msg.Subject, ": ", err)
+			return
+		}
+		handle(name, handler, event)
+	})
+	return err
+}
+
+// Close lets the messages already received be handled before
+// disconnecting.
+func (b *NATSBus) Close() error {
+	if b.conn.IsClosed() {
+		return nil
+	}
+	if err := b.conn.Drain(); err != nil {
+		return err
+	}
+	<-b.closed
+	return nil
+}
+
+func (b *NATSBus) subject(eventType string) string {
+	return strings.TrimSuffix(b.prefix, ".") + "." + eventType
+}
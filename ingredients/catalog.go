@@ -0,0 +1,136 @@
+package ingredients
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hopk8412/table-recipes-api/models"
+)
+
+// Catalog maps free text ingredient lines to canonical catalog entries by
+// their normalized name, synonyms and plural forms.
+type Catalog struct {
+	byName map[string]*models.CatalogIngredient
+	// names sorted longest first, so suffix matching finds the most
+	// specific entry.
+	names []string
+}
+
+func NewCatalog(entries []models.CatalogIngredient) *Catalog {
+	catalog := &Catalog{byName: map[string]*models.CatalogIngredient{}}
+	for i := range entries {
+		entry := &entries[i]
+		forms := append([]string{entry.Name}, entry.Synonyms...)
+		forms = append(forms, entry.PluralForms...)
+		for _, form := range forms {
+			normalized := normalizeName(form)
+			if normalized == "" {
+				continue
+			}
+			if _, taken := catalog.byName[normalized]; !taken {
+				catalog.byName[normalized] = entry
+				catalog.names = append(catalog.names, normalized)
+			}
+		}
+	}
+	sort.Slice(catalog.names, func(i, j int) bool {
+		return len(catalog.names[i]) > len(catalog.names[j])
+	})
+	return catalog
+}
+
+// Lookup returns the catalog entry for an ingredient line, or nil. An exact
+// match on the normalized name wins, otherwise the longest entry the name
+// ends with, so "smoked paprika" finds "paprika" when there's no entry of
+// its own.
+func (catalog *Catalog) Lookup(line string) *models.CatalogIngredient {
+	if catalog == nil {
+		return nil
+	}
+	return catalog.LookupNormalized(Normalize(line))
+}
+
+// LookupNormalized is Lookup for a name Normalize already returned.
+func (catalog *Catalog) LookupNormalized(normalized string) *models.CatalogIngredient {
+	if catalog == nil || normalized == "" {
+		return nil
+	}
+	if entry, ok := catalog.byName[normalized]; ok {
+		return entry
+	}
+	for _, name := range catalog.names {
+		if strings.HasSuffix(normalized, " "+name) {
+			return catalog.byName[name]
+		}
+	}
+	return nil
+}
+
+// Owner returns the entry that has name as its name, a synonym or a plural
+// form, or nil.
+func (catalog *Catalog) Owner(name string) *models.CatalogIngredient {
+	if catalog == nil {
+		return nil
+	}
+	return catalog.byName[normalizeName(name)]
+}
+
+// Forms lists every normalized name that maps to the entry.
+func (catalog *Catalog) Forms(entry *models.CatalogIngredient) []string {
+	if catalog == nil {
+		return nil
+	}
+	var forms []string
+	for _, name := range catalog.names {
+		if catalog.byName[name] == entry {
+			forms = append(forms, name)
+		}
+	}
+	return forms
+}
+
+// normalizeName normalizes a catalog name the same way as an ingredient
+// line, but without trying to read a quantity off the front of it.
+func normalizeName(name string) string {
+	name = nonWordPattern.ReplaceAllString(strings.ToLower(name), " ")
+	var words []string
+	for _, word := range strings.Fields(name) {
+		if !descriptors[word] {
+			words = append(words, Singular(word))
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+var current struct {
+	sync.RWMutex
+	catalog *Catalog
+}
+
+// SetCatalog replaces the catalog used by Canonical and LookupCatalog.
+func SetCatalog(catalog *Catalog) {
+	current.Lock()
+	defer current.Unlock()
+	current.catalog = catalog
+}
+
+func CurrentCatalog() *Catalog {
+	current.RLock()
+	defer current.RUnlock()
+	return current.catalog
+}
+
+// LookupCatalog looks the line up in the current catalog.
+func LookupCatalog(line string) *models.CatalogIngredient {
+	return CurrentCatalog().Lookup(line)
+}
+
+// Canonical is the name to compare ingredients by: the catalog entry's name
+// when the line is in the catalog, the normalized line otherwise.
+func Canonical(line string) string {
+	if entry := LookupCatalog(line); entry != nil {
+		return normalizeName(entry.Name)
+	}
+	return Normalize(line)
+}
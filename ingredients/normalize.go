@@ -0,0 +1,96 @@
+package ingredients
+
+import (
+	"regexp"
+	"strings"
+)
+
+// descriptors are words that describe how an ingredient is prepared or
+// bought rather than what it is, so "2 boneless skinless chicken breasts"
+// and "chicken breast, diced" normalize to the same thing.
+var descriptors = map[string]bool{
+	"boneless": true, "skinless": true, "bone-in": true, "skin-on": true,
+	"fresh": true, "freshly": true, "frozen": true, "thawed": true, "dried": true, "canned": true,
+	"chopped": true, "diced": true, "minced": true, "sliced": true, "grated": true, "shredded": true,
+	"crushed": true, "peeled": true, "seeded": true, "cubed": true, "halved": true, "quartered": true,
+	"finely": true, "roughly": true, "coarsely": true, "thinly": true, "thickly": true,
+	"large": true, "medium": true, "small": true, "extra-large": true, "jumbo": true,
+	"ripe": true, "organic": true, "raw": true, "cooked": true, "uncooked": true,
+	"softened": true, "melted": true, "room": true, "temperature": true, "cold": true, "warm": true,
+	"packed": true, "heaping": true, "level": true, "optional": true, "about": true,
+	"plus": true, "more": true, "extra": true, "to": true, "taste": true, "for": true,
+	"garnish": true, "serving": true, "and": true, "or": true, "of": true, "the": true,
+}
+
+// uncountable words end in "s" but aren't plurals.
+var uncountable = map[string]bool{
+	"asparagus": true, "couscous": true, "hummus": true, "molasses": true, "swiss": true,
+	"citrus": true, "grits": true, "oats": true, "brussels": true,
+}
+
+// irregular plurals that the suffix rules get wrong.
+var irregular = map[string]string{
+	"leaves": "leaf", "halves": "half", "loaves": "loaf", "knives": "knife",
+	"cookies": "cookie", "brownies": "brownie",
+}
+
+var nonWordPattern = regexp.MustCompile(`[^a-z0-9\- ]+`)
+
+// Normalize reduces an ingredient line to a canonical name for matching:
+// quantity, unit, preparation notes and descriptive words are dropped and
+// every word is made singular, so "2 boneless chicken breasts, cubed" and
+// "chicken breast" both become "chicken breast".
+func Normalize(raw string) string {
+	name := ParseLine(raw).Name
+	name = nonWordPattern.ReplaceAllString(name, " ")
+	var words []string
+	for _, word := range strings.Fields(name) {
+		if descriptors[word] {
+			continue
+		}
+		words = append(words, Singular(word))
+	}
+	return strings.Join(words, " ")
+}
+
+// Singular returns the singular form of an English noun, good enough for
+// ingredient names rather than English in general.
+func Singular(word string) string {
+	if singular, ok := irregular[word]; ok {
+		return singular
+	}
+	if len(word) < 4 || uncountable[word] {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "oes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "sses"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// Covers reports whether having the normalized ingredient have on hand is
+// enough for the normalized recipe ingredient need. Names match when one
+// ends with the other, so the last word (the thing itself) has to agree:
+// "flour" covers "all-purpose flour" and vice versa, but "chicken" doesn't
+// cover "chicken broth".
+func Covers(have string, need string) bool {
+	if have == "" || need == "" {
+		return false
+	}
+	return have == need || strings.HasSuffix(need, " "+have) || strings.HasSuffix(have, " "+need)
+}
+
+// staples are assumed to be in every kitchen.
+var staples = map[string]bool{"water": true, "salt": true, "pepper": true, "black pepper": true, "salt pepper": true, "ice": true}
+
+func IsStaple(normalized string) bool {
+	return staples[normalized]
+}
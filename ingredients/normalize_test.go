@@ -0,0 +1,44 @@
+package ingredients
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"2 boneless skinless chicken breasts, cubed", "chicken breast"},
+		{"chicken breast", "chicken breast"},
+		{"3 large tomatoes", "tomato"},
+		{"1 cup fresh blueberries", "blueberry"},
+		{"2 bay leaves", "bay leaf"},
+		{"1 lb asparagus", "asparagus"},
+		{"1/2 cup rolled oats", "rolled oats"},
+		{"4 peaches", "peach"},
+		{"Salt and pepper, to taste", "salt pepper"},
+	}
+	for _, test := range tests {
+		if got := Normalize(test.raw); got != test.want {
+			t.Errorf("Normalize(%q) = %q, want %q", test.raw, got, test.want)
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		have, need string
+		want       bool
+	}{
+		{"flour", "all-purpose flour", true},
+		{"all-purpose flour", "flour", true},
+		{"chicken", "chicken broth", false},
+		{"egg", "egg", true},
+		{"", "egg", false},
+		{"egg", "", false},
+	}
+	for _, test := range tests {
+		if got := Covers(test.have, test.need); got != test.want {
+			t.Errorf("Covers(%q, %q) = %v, want %v", test.have, test.need, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ingredients
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Line is a free text ingredient line split into its parts, e.g.
+// "1 1/2 cups all-purpose flour, sifted" gives Quantity 1.5, Unit "cup" and
+// Name "all-purpose flour".
+type Line struct {
+	Raw      string
+	Quantity float64
+	Unit     string
+	Name     string
+}
+
+var unicodeFractions = map[string]string{
+	"¼": " 1/4", "½": " 1/2", "¾": " 3/4", "⅓": " 1/3", "⅔": " 2/3",
+	"⅛": " 1/8", "⅜": " 3/8", "⅝": " 5/8", "⅞": " 7/8",
+}
+
+// unitAliases maps the ways units get written to the canonical unit name.
+var unitAliases = map[string]string{
+	"cup": "cup", "cups": "cup", "c": "cup",
+	"tablespoon": "tbsp", "tablespoons": "tbsp", "tbsp": "tbsp", "tbs": "tbsp", "tbl": "tbsp",
+	"teaspoon": "tsp", "teaspoons": "tsp", "tsp": "tsp",
+	"gram": "g", "grams": "g", "g": "g", "gr": "g",
+	"kilogram": "kg", "kilograms": "kg", "kg": "kg",
+	"ounce": "oz", "ounces": "oz", "oz": "oz",
+	"pound": "lb", "pounds": "lb", "lb": "lb", "lbs": "lb",
+	"milliliter": "ml", "milliliters": "ml", "millilitre": "ml", "millilitres": "ml", "ml": "ml",
+	"liter": "l", "liters": "l", "litre": "l", "litres": "l", "l": "l",
+	"pinch": "pinch", "pinches": "pinch", "dash": "pinch",
+	"clove": "clove", "cloves": "clove",
+	"slice": "slice", "slices": "slice",
+	"can": "can", "cans": "can", "tin": "can", "tins": "can",
+	"stick": "stick", "sticks": "stick",
+	"piece": "each", "pieces": "each",
+}
+
+var quantityPattern = regexp.MustCompile(`^(\d+\s+\d+/\d+|\d+/\d+|\d*\.\d+|\d+)(\s*(-|to)\s*(\d+\s+\d+/\d+|\d+/\d+|\d*\.\d+|\d+))?`)
+var parenthesesPattern = regexp.MustCompile(`\([^)]*\)`)
+
+// ParseLine splits an ingredient line into quantity, unit and name. Lines
+// without a quantity ("salt to taste") come back with Quantity 0, and
+// counted items ("2 eggs") with Unit "each".
+func ParseLine(raw string) Line {
+	line := Line{Raw: raw}
+	text := strings.ToLower(strings.TrimSpace(raw))
+	for fraction, replacement := range unicodeFractions {
+		text = strings.ReplaceAll(text, fraction, replacement)
+	}
+	text = strings.TrimSpace(text)
+
+	if match := quantityPattern.FindStringSubmatch(text); match != nil {
+		line.Quantity = parseNumber(match[1])
+		// For ranges like "2-3 cloves" use the midpoint.
+		if match[4] != "" {
+			line.Quantity = (line.Quantity + parseNumber(match[4])) / 2
+		}
+		text = strings.TrimSpace(text[len(match[0]):])
+	} else if strings.HasPrefix(text, "a ") || strings.HasPrefix(text, "an ") {
+		line.Quantity = 1
+		text = strings.TrimSpace(text[strings.Index(text, " "):])
+	}
+
+	// "1 (14 oz) can tomatoes": the package size is more useful than the
+	// count, so pull it out before the parentheses get dropped.
+	if inner := parenthesesPattern.FindString(text); inner != "" && line.Quantity > 0 {
+		packaged := ParseLine(strings.Trim(inner, "()"))
+		if packaged.Quantity > 0 && isMassOrVolume(packaged.Unit) {
+			line.Quantity *= packaged.Quantity
+			line.Unit = packaged.Unit
+			text = strings.TrimSpace(strings.Replace(text, inner, "", 1))
+			text = strings.TrimSpace(strings.TrimPrefix(text, "can "))
+		}
+	}
+	text = parenthesesPattern.ReplaceAllString(text, "")
+
+	if line.Unit == "" {
+		fields := strings.Fields(text)
+		if len(fields) > 0 {
+			if unit, ok := unitAliases[strings.TrimSuffix(fields[0], ".")]; ok && (line.Quantity > 0 || unit == "pinch") {
+				line.Unit = unit
+				text = strings.Join(fields[1:], " ")
+			} else if line.Quantity > 0 {
+				line.Unit = "each"
+			}
+		}
+		text = strings.TrimSpace(strings.TrimPrefix(text, "of "))
+	}
+
+	// Preparation notes after the first comma aren't part of the name.
+	if comma := strings.Index(text, ","); comma >= 0 {
+		text = text[:comma]
+	}
+	line.Name = strings.Join(strings.Fields(text), " ")
+	return line
+}
+
+func isMassOrVolume(unit string) bool {
+	switch unit {
+	case "g", "kg", "oz", "lb", "ml", "l", "cup", "tbsp", "tsp":
+		return true
+	}
+	return false
+}
+
+func parseNumber(s string) float64 {
+	total := 0.0
+	for _, part := range strings.Fields(s) {
+		if slash := strings.Index(part, "/"); slash >= 0 {
+			numerator, err1 := strconv.ParseFloat(part[:slash], 64)
+			denominator, err2 := strconv.ParseFloat(part[slash+1:], 64)
+			if err1 == nil && err2 == nil && denominator != 0 {
+				total += numerator / denominator
+			}
+			continue
+		}
+		if value, err := strconv.ParseFloat(part, 64); err == nil {
+			total += value
+		}
+	}
+	return total
+}
@@ -0,0 +1,34 @@
+package ingredients
+
+import "testing"
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		raw      string
+		quantity float64
+		unit     string
+		name     string
+	}{
+		{"1 1/2 cups all-purpose flour, sifted", 1.5, "cup", "all-purpose flour"},
+		{"2 tablespoons olive oil", 2, "tbsp", "olive oil"},
+		{"½ tsp salt", 0.5, "tsp", "salt"},
+		{"1 ½ cups milk", 1.5, "cup", "milk"},
+		{"2 eggs", 2, "each", "eggs"},
+		{"2-3 cloves garlic, minced", 2.5, "clove", "garlic"},
+		{"1 to 2 tbsp. honey", 1.5, "tbsp", "honey"},
+		{"1 (14 oz) can diced tomatoes", 14, "oz", "diced tomatoes"},
+		{"2 (400 g) tins chickpeas", 800, "g", "tins chickpeas"},
+		{"a pinch of nutmeg", 1, "pinch", "nutmeg"},
+		{"pinch of salt", 0, "pinch", "salt"},
+		{".5 lb ground beef", 0.5, "lb", "ground beef"},
+		{"500g flour", 500, "g", "flour"},
+		{"salt to taste", 0, "", "salt to taste"},
+		{"", 0, "", ""},
+	}
+	for _, test := range tests {
+		got := ParseLine(test.raw)
+		if got.Quantity != test.quantity || got.Unit != test.unit || got.Name != test.name {
+			t.Errorf("ParseLine(%q) = %v %q %q, want %v %q %q", test.raw, got.Quantity, got.Unit, got.Name, test.quantity, test.unit, test.name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package ingredients
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale multiplies the quantity at the start of an ingredient line by factor,
+// leaving the rest of the line as written, so "1 1/2 cups flour" scaled by 2
+// is "3 cups flour". Lines without a quantity are returned unchanged.
+func Scale(raw string, factor float64) string {
+	text := strings.TrimSpace(raw)
+	for fraction, replacement := range unicodeFractions {
+		text = strings.ReplaceAll(text, fraction, replacement)
+	}
+	text = strings.TrimSpace(text)
+
+	match := quantityPattern.FindStringSubmatch(text)
+	if match == nil || factor == 1 {
+		return raw
+	}
+	scaled := formatQuantity(parseNumber(match[1]) * factor)
+	if match[4] != "" {
+		scaled += "-" + formatQuantity(parseNumber(match[4])*factor)
+	}
+	return scaled + text[len(match[0]):]
+}
+
+var kitchenFractions = []struct {
+	value float64
+	text  string
+}{
+	{1.0 / 8, "1/8"}, {1.0 / 4, "1/4"}, {1.0 / 3, "1/3"}, {3.0 / 8, "3/8"}, {1.0 / 2, "1/2"},
+	{5.0 / 8, "5/8"}, {2.0 / 3, "2/3"}, {3.0 / 4, "3/4"}, {7.0 / 8, "7/8"},
+}
+
+// formatQuantity writes amounts the way recipes do, as whole numbers and
+// common fractions where they're close enough, e.g. 1.5 is "1 1/2".
+func formatQuantity(quantity float64) string {
+	whole, fraction := math.Modf(quantity)
+	if fraction < 0.03 {
+		return strconv.Itoa(int(whole))
+	}
+	if fraction > 0.97 {
+		return strconv.Itoa(int(whole) + 1)
+	}
+	for _, common := range kitchenFractions {
+		if math.Abs(fraction-common.value) < 0.03 {
+			if whole == 0 {
+				return common.text
+			}
+			return strconv.Itoa(int(whole)) + " " + common.text
+		}
+	}
+	return strconv.FormatFloat(math.Round(quantity*100)/100, 'f', -1, 64)
+}
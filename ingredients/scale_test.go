@@ -0,0 +1,44 @@
+package ingredients
+
+import "testing"
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		raw    string
+		factor float64
+		want   string
+	}{
+		{"1 1/2 cups flour", 2, "3 cups flour"},
+		{"1 cup milk", 0.5, "1/2 cup milk"},
+		{"3 eggs", 1.0 / 3, "1 eggs"},
+		{"2-3 cloves garlic", 2, "4-6 cloves garlic"},
+		{"½ tsp salt", 3, "1 1/2 tsp salt"},
+		{"1 tbsp sugar", 1.4, "1 3/8 tbsp sugar"},
+		{"2 cups stock", 1, "2 cups stock"},
+		{"salt to taste", 2, "salt to taste"},
+	}
+	for _, test := range tests {
+		if got := Scale(test.raw, test.factor); got != test.want {
+			t.Errorf("Scale(%q, %v) = %q, want %q", test.raw, test.factor, got, test.want)
+		}
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	tests := []struct {
+		quantity float64
+		want     string
+	}{
+		{2, "2"},
+		{0.25, "1/4"},
+		{1.5, "1 1/2"},
+		{0.33, "1/3"},
+		{2.99, "3"},
+		{1.4, "1 3/8"},
+	}
+	for _, test := range tests {
+		if got := formatQuantity(test.quantity); got != test.want {
+			t.Errorf("formatQuantity(%v) = %q, want %q", test.quantity, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const pollInterval = 2 * time.Second
+
+// A running job that hasn't sent a heartbeat for this long is assumed to
+// belong to a worker that died and is handed out again.
+const leaseTimeout = time.Minute
+
+type Pool struct {
+	workers int
+	stop    chan struct{}
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+func NewPool(workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pool{
+		workers: workers,
+		stop:    make(chan struct{}),
+		running: map[string]context.CancelFunc{},
+	}
+}
+
+func (p *Pool) Start() {
+	log.Println("Starting ", p.workers, " job workers...")
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+}
+
+// Shutdown stops workers from claiming new jobs and waits for the jobs in
+// flight to finish. If ctx expires first, the remaining jobs are interrupted
+// and put back on the queue so another worker can pick them up.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	close(p.stop)
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		log.Println("Job workers did not drain in time, interrupting running jobs...")
+		p.mu.Lock()
+		for _, cancel := range p.running {
+			cancel()
+		}
+		p.mu.Unlock()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		job, err := p.claim()
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Println("Error claiming job: ", err)
+			}
+			select {
+			case <-p.stop:
+				return
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+		p.run(job)
+	}
+}
+
+func (p *Pool) claim() (*models.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	filter := bson.M{"$or": []bson.M{
+		{"status": models.JobStatusQueued, "runAt": bson.M{"$lte": now}},
+		{"status": models.JobStatusRunning, "heartbeatAt": bson.M{"$lt": now.Add(-leaseTimeout)}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": models.JobStatusRunning, "startedAt": now, "heartbeatAt": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"runAt": 1}).SetReturnDocument(options.After)
+
+	var job models.Job
+	if err := jobsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (p *Pool) run(job *models.Job) {
+	handler, ok := handlers[job.Type]
+	if !ok {
+		p.finish(job, nil, ErrUnknownJobType, false)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	p.running[job.Id] = cancel
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.running, job.Id)
+		p.mu.Unlock()
+		cancel()
+	}()
+
+	cancelledByUser := make(chan struct{})
+	go p.heartbeat(ctx, job.Id, cancel, cancelledByUser)
+
+	log.Println("Running job ", job.Id, " of type ", job.Type, " (attempt ", job.Attempts, ")...")
+	result, err := handler(ctx, job, func(percent int) {
+		p.setProgress(job.Id, percent)
+	})
+
+	select {
+	case <-cancelledByUser:
+		p.finish(job, nil, nil, true)
+		return
+	default:
+	}
+	if err != nil && ctx.Err() != nil {
+		// Interrupted by Shutdown, give the attempt back and requeue.
+		p.requeue(job)
+		return
+	}
+	p.finish(job, result, err, false)
+}
+
+// heartbeat keeps the job's lease alive and cancels its context once a
+// cancellation has been requested through Cancel.
+func (p *Pool) heartbeat(ctx context.Context, id string, cancel context.CancelFunc, cancelledByUser chan struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		dbCtx, dbCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var job models.Job
+		err := jobsCollection.FindOneAndUpdate(dbCtx, bson.M{"_id": id},
+			bson.M{"$set": bson.M{"heartbeatAt": time.Now().UTC()}}).Decode(&job)
+		dbCancel()
+		if err == nil && job.CancelRequested {
+			log.Println("Cancellation requested for job ", id)
+			close(cancelledByUser)
+			cancel()
+			return
+		}
+	}
+}
+
+func (p *Pool) setProgress(id string, percent int) {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := jobsCollection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"progress": percent, "heartbeatAt": time.Now().UTC()}})
+	if err != nil {
+		log.Println("Error updating progress for job ", id, ": ", err)
+	}
+}
+
+func (p *Pool) requeue(job *models.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := jobsCollection.UpdateByID(ctx, job.Id, bson.M{
+		"$set":   bson.M{"status": models.JobStatusQueued, "runAt": time.Now().UTC()},
+		"$unset": bson.M{"heartbeatAt": ""},
+		"$inc":   bson.M{"attempts": -1},
+	})
+	if err != nil {
+		log.Println("Error requeueing job ", job.Id, ": ", err)
+	}
+}
+
+func (p *Pool) finish(job *models.Job, result map[string]interface{}, jobErr error, cancelled bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	set := bson.M{}
+	switch {
+	case cancelled:
+		set["status"] = models.JobStatusCancelled
+		set["finishedAt"] = now
+	case jobErr == nil:
+		set["status"] = models.JobStatusSucceeded
+		set["progress"] = 100
+		set["result"] = result
+		set["finishedAt"] = now
+	case job.Attempts < job.MaxAttempts && jobErr != ErrUnknownJobType:
+		log.Println("Job ", job.Id, " failed, retrying: ", jobErr)
+		set["status"] = models.JobStatusQueued
+		set["error"] = jobErr.Error()
+		set["runAt"] = now.Add(backoff(job.Attempts))
+	default:
+		log.Println("Job ", job.Id, " failed: ", jobErr)
+		set["status"] = models.JobStatusFailed
+		set["error"] = jobErr.Error()
+		set["finishedAt"] = now
+	}
+	_, err := jobsCollection.UpdateByID(ctx, job.Id, bson.M{"$set": set, "$unset": bson.M{"heartbeatAt": ""}})
+	if err != nil {
+		log.Println("Error recording outcome of job ", job.Id, ": ", err)
+	}
+}
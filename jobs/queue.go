@@ -0,0 +1,147 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ProgressFunc lets a running handler report how far along it is (0-100).
+type ProgressFunc func(percent int)
+
+// HandlerFunc does the actual work for a job type. The returned map is stored
+// as the job result when the handler succeeds.
+type HandlerFunc func(ctx context.Context, job *models.Job, progress ProgressFunc) (map[string]interface{}, error)
+
+const defaultMaxAttempts = 3
+
+var ErrUnknownJobType = errors.New("unknown job type")
+var ErrJobFinished = errors.New("job has already finished")
+
+var jobsCollection *mongo.Collection = configs.GetCollection(configs.DB, "jobs")
+
+var handlers = map[string]HandlerFunc{}
+
+// internal job types are queued by the API itself and can't be posted by
+// users.
+var internal = map[string]bool{}
+
+// Register makes a job type available to Enqueue and the worker pool. It is
+// meant to be called during startup, before the pool is started.
+func Register(jobType string, handler HandlerFunc) {
+	handlers[jobType] = handler
+}
+
+// RegisterInternal is Register for job types only the API may enqueue.
+func RegisterInternal(jobType string, handler HandlerFunc) {
+	Register(jobType, handler)
+	internal[jobType] = true
+}
+
+func IsInternal(jobType string) bool {
+	return internal[jobType]
+}
+
+func IsRegistered(jobType string) bool {
+	_, ok := handlers[jobType]
+	return ok
+}
+
+func Enqueue(ctx context.Context, jobType string, ownerId string, payload map[string]interface{}) (*models.Job, error) {
+	return EnqueueWithAttempts(ctx, jobType, ownerId, payload, defaultMaxAttempts)
+}
+
+// EnqueueWithAttempts is Enqueue for jobs that should be retried more, or
+// less, than usual before they are marked failed.
+func EnqueueWithAttempts(ctx context.Context, jobType string, ownerId string, payload map[string]interface{}, maxAttempts int) (*models.Job, error) {
+	if !IsRegistered(jobType) {
+		return nil, ErrUnknownJobType
+	}
+	now := time.Now().UTC()
+	job := models.Job{
+		Id:          primitive.NewObjectID().Hex(),
+		Type:        jobType,
+		Status:      models.JobStatusQueued,
+		OwnerId:     ownerId,
+		Payload:     payload,
+		MaxAttempts: maxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+	}
+	if _, err := jobsCollection.InsertOne(ctx, job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func FindById(ctx context.Context, id string) (*models.Job, error) {
+	var job models.Job
+	if err := jobsCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func FindByOwner(ctx context.Context, ownerId string) ([]models.Job, error) {
+	var jobs []models.Job
+	results, err := jobsCollection.Find(ctx, bson.M{"ownerId": ownerId})
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+	for results.Next(ctx) {
+		var singleJob models.Job
+		if err = results.Decode(&singleJob); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, singleJob)
+	}
+	return jobs, nil
+}
+
+// Cancel stops a job. Queued jobs are cancelled straight away; running jobs
+// are flagged and the worker running them cancels the handler's context.
+func Cancel(ctx context.Context, id string) (*models.Job, error) {
+	now := time.Now().UTC()
+	result, err := jobsCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.JobStatusQueued},
+		bson.M{"$set": bson.M{"status": models.JobStatusCancelled, "cancelRequested": true, "finishedAt": now}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		result, err = jobsCollection.UpdateOne(ctx,
+			bson.M{"_id": id, "status": models.JobStatusRunning},
+			bson.M{"$set": bson.M{"cancelRequested": true}})
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 0 {
+			if _, err := FindById(ctx, id); err != nil {
+				return nil, err
+			}
+			return nil, ErrJobFinished
+		}
+	}
+	return FindById(ctx, id)
+}
+
+// backoff returns how long to wait before retrying a job that has failed
+// the given number of times: 5s, 10s, 20s... capped at five minutes.
+func backoff(attempts int) time.Duration {
+	delay := 5 * time.Second
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= 5*time.Minute {
+			return 5 * time.Minute
+		}
+	}
+	return delay
+}
@@ -1,9 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/events"
+	"github.com/hopk8412/table-recipes-api/jobs"
+	"github.com/hopk8412/table-recipes-api/notifications"
+	"github.com/hopk8412/table-recipes-api/openapi"
 	"golang.org/x/exp/slices"
 
 	"github.com/hopk8412/table-recipes-api/routes"
@@ -11,6 +24,7 @@ import (
 	"github.com/hopk8412/table-recipes-api/controllers"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -19,6 +33,7 @@ func main() {
 	configs.ConnectDB()
 
 	router.Use(corsMiddleware())
+	router.Use(openapi.Validator())
 
 	prefix := "/api/v1"
 	routes.RecipeRoutes(router)
@@ -31,11 +46,231 @@ func main() {
 	router.POST(prefix+"/users/:id/recipes", controllers.AddOrRemoveRecipeToUserFavorites())
 	router.DELETE(prefix+"/recipes/:id", controllers.DeleteRecipeById())
 	router.PUT(prefix+"/recipes/:id", controllers.UpdateRecipeById())
+	router.GET(prefix+"/users/:id/preferences", controllers.GetUserPreferences())
+	router.PUT(prefix+"/users/:id/preferences", controllers.UpdateUserPreferences())
+	router.GET(prefix+"/users/:id/pantry", controllers.GetUserPantry())
+	router.PUT(prefix+"/users/:id/pantry", controllers.UpdateUserPantry())
+	router.GET(prefix+"/users/:id/pantry/matches", controllers.GetPantryMatches())
+	router.GET(prefix+"/users/:id/recommendations", controllers.GetUserRecommendations())
+	router.GET(prefix+"/users/:id/feed", controllers.GetUserFeed())
+	router.GET(prefix+"/users/:id/feed/events", controllers.StreamUserFeed())
+	router.GET(prefix+"/users/:id/following", controllers.GetFollowing())
+	router.GET(prefix+"/users/:id/followers", controllers.GetFollowers())
+	router.PUT(prefix+"/users/:id/following/:authorId", controllers.FollowAuthor())
+	router.DELETE(prefix+"/users/:id/following/:authorId", controllers.UnfollowAuthor())
+	router.GET(prefix+"/authors/:id", controllers.GetAuthor())
+	router.PUT(prefix+"/authors/:id", controllers.UpdateAuthorProfile())
+	router.GET(prefix+"/users/:id/notifications", controllers.GetNotifications())
+	router.POST(prefix+"/users/:id/notifications/read", controllers.MarkAllNotificationsRead())
+	router.POST(prefix+"/users/:id/notifications/:notificationId/read", controllers.MarkNotificationRead())
+	router.GET(prefix+"/users/:id/notifications/preferences", controllers.GetNotificationPreferences())
+	router.PUT(prefix+"/users/:id/notifications/preferences", controllers.UpdateNotificationPreferences())
+	router.GET(prefix+"/recipes/:id/reviews", controllers.GetRecipeReviews())
+	router.GET(prefix+"/recipes/:id/events", controllers.StreamRecipeEvents())
+	router.POST(prefix+"/recipes/:id/reviews", controllers.PostRecipeReview())
+	router.DELETE(prefix+"/recipes/:id/reviews", controllers.DeleteRecipeReview())
+	router.GET(prefix+"/users/:id/sessions", controllers.GetUserCookingSessions())
+	router.GET(prefix+"/recipes/shared", controllers.GetSharedRecipes())
+	router.GET(prefix+"/recipes/:id/collaborators", controllers.GetRecipeCollaborators())
+	router.PUT(prefix+"/recipes/:id/collaborators/:userId", controllers.UpdateCollaboratorRole())
+	router.DELETE(prefix+"/recipes/:id/collaborators/:userId", controllers.RemoveCollaborator())
+	router.POST(prefix+"/recipes/:id/invitations", controllers.InviteCollaborator())
+	router.DELETE(prefix+"/recipes/:id/invitations/:invitationId", controllers.RevokeInvitation())
+	router.GET(prefix+"/invitations", controllers.GetMyInvitations())
+	router.POST(prefix+"/invitations/:id/accept", controllers.AcceptInvitation())
+	router.POST(prefix+"/invitations/:id/decline", controllers.DeclineInvitation())
+	router.POST(prefix+"/recipes/:id/publish", controllers.PublishRecipe())
+	router.POST(prefix+"/recipes/:id/archive", controllers.ArchiveRecipe())
+	router.POST(prefix+"/recipes/:id/fork", controllers.ForkRecipe())
+	router.GET(prefix+"/recipes/:id/lineage", controllers.GetRecipeLineage())
+	router.GET(prefix+"/recipes/:id/diff", controllers.GetRecipeDiff())
+	router.GET(prefix+"/recipes/:id/session", controllers.GetCookingSession())
+	router.POST(prefix+"/recipes/:id/session", controllers.StartCookingSession())
+	router.PUT(prefix+"/recipes/:id/session", controllers.UpdateCookingSession())
+	router.DELETE(prefix+"/recipes/:id/session", controllers.FinishCookingSession())
+	router.GET(prefix+"/dietary", controllers.GetDietaryDictionary())
+	router.GET(prefix+"/ingredients", controllers.GetCatalogIngredients())
+	router.GET(prefix+"/ingredients/unmatched", controllers.GetUnmatchedIngredients())
+	router.GET(prefix+"/ingredients/:id", controllers.GetCatalogIngredientById())
+	router.POST(prefix+"/ingredients", controllers.PostCatalogIngredient())
+	router.PUT(prefix+"/ingredients/:id", controllers.UpdateCatalogIngredientById())
+	router.DELETE(prefix+"/ingredients/unmatched/:id", controllers.DeleteUnmatchedIngredient())
+	router.DELETE(prefix+"/ingredients/:id", controllers.DeleteCatalogIngredientById())
+	router.GET(prefix+"/jobs", controllers.GetJobs())
+	router.GET(prefix+"/jobs/:id", controllers.GetJobById())
+	router.GET(prefix+"/jobs/:id/result", controllers.GetJobResult())
+	router.POST(prefix+"/jobs", controllers.PostJob())
+	router.POST(prefix+"/jobs/:id/cancel", controllers.CancelJob())
+	router.GET(prefix+"/webhooks", controllers.GetWebhooks())
+	router.GET(prefix+"/webhooks/:id", controllers.GetWebhookById())
+	router.POST(prefix+"/webhooks", controllers.PostWebhook())
+	router.PUT(prefix+"/webhooks/:id", controllers.UpdateWebhookById())
+	router.DELETE(prefix+"/webhooks/:id", controllers.DeleteWebhookById())
+	router.GET(prefix+"/webhooks/:id/deliveries", controllers.GetWebhookDeliveries())
+	router.POST(prefix+"/webhooks/:id/deliveries/:deliveryId/redeliver", controllers.RedeliverWebhook())
+	router.GET(prefix+"/graphql", controllers.GraphQL())
+	router.POST(prefix+"/graphql", controllers.GraphQL())
+	router.GET(prefix+"/openapi.json", controllers.OpenAPISpec())
+	router.GET(prefix+"/docs/*filepath", controllers.SwaggerUI())
 	router.NoRoute(func(c *gin.Context) {
 		c.IndentedJSON(http.StatusNotFound, gin.H{"message": "We couldn't find the page you requested!"})
 	})
 
-	router.Run(":8080")
+	controllers.RegisterRecipeJobs()
+	controllers.RegisterNotificationJobs()
+	controllers.RegisterWebhookJobs()
+	if smtpSettings := configs.SMTP(); smtpSettings != nil {
+		notifications.Register(notifications.NewSMTPChannel(*smtpSettings))
+	}
+	notifications.Register(notifications.NewWebhookChannel())
+	startupCtx, startupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := controllers.LoadIngredientCatalog(startupCtx); err != nil {
+		log.Println("Error loading ingredient catalog: ", err)
+	}
+	if err := controllers.EnsureRecipeIndexes(startupCtx); err != nil {
+		log.Println("Error creating recipe indexes: ", err)
+	}
+	if err := controllers.EnsureRecipeJobIndexes(startupCtx); err != nil {
+		log.Println("Error creating recipe export indexes: ", err)
+	}
+	if err := controllers.MigrateFavorites(startupCtx); err != nil {
+		log.Println("Error migrating favorites: ", err)
+	}
+	if err := controllers.QueueDietaryBackfill(startupCtx); err != nil {
+		log.Println("Error queueing allergen detection: ", err)
+	}
+	if err := controllers.EnsureCookingSessionIndexes(startupCtx); err != nil {
+		log.Println("Error creating cooking session indexes: ", err)
+	}
+	if err := controllers.EnsureFeedIndexes(startupCtx); err != nil {
+		log.Println("Error creating feed indexes: ", err)
+	}
+	if err := controllers.EnsureNotificationIndexes(startupCtx); err != nil {
+		log.Println("Error creating notification indexes: ", err)
+	}
+	if err := controllers.EnsureWebhookIndexes(startupCtx); err != nil {
+		log.Println("Error creating webhook indexes: ", err)
+	}
+	if err := controllers.EnsureLiveIndexes(startupCtx); err != nil {
+		log.Println("Error creating live event indexes: ", err)
+	}
+	startupCancel()
+	workerPool := jobs.NewPool(configs.JobWorkerCount())
+	workerPool.Start()
+
+	eventBus, err := newEventBus()
+	if err != nil {
+		log.Fatal("Error connecting to event bus: ", err)
+	}
+	streamCtx, stopStreams := context.WithCancel(context.Background())
+	var streams sync.WaitGroup
+	// Catalog changes made through other copies of the API
+	streams.Add(1)
+	go func() {
+		defer streams.Done()
+		controllers.PollIngredientCatalog(streamCtx)
+	}()
+	if configs.ChangeStreamsEnabled() {
+		if configs.EventBusReachesAllReplicas() {
+			if err := controllers.SubscribeToEvents(eventBus); err != nil {
+				log.Println("Error subscribing to events: ", err)
+			}
+		} else {
+			log.Println("Event bus only reaches this process, handlers keep sending recipe webhooks and updates themselves")
+		}
+		for _, source := range controllers.EventSources() {
+			streams.Add(1)
+			go func(source events.Source) {
+				defer streams.Done()
+				events.Watch(streamCtx, eventBus, source)
+			}(source)
+		}
+	}
+
+	srv := &http.Server{Addr: ":8080", Handler: router}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	grpcListener, err := net.Listen("tcp", ":"+configs.GrpcPort())
+	if err != nil {
+		log.Fatal("Error listening for gRPC: ", err)
+	}
+	grpcServer := controllers.NewGrpcServer()
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	// Wait for an interrupt, then stop taking requests and let the job
+	// workers drain before exiting.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	log.Println("Shutting down...")
+
+	// Each stage gets a budget of its own, so slow HTTP requests can't use
+	// up the time the job workers have to finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	controllers.CloseLiveStreams()
+	if err := srv.Shutdown(shutdownCtx); errors.Is(err, context.DeadlineExceeded) {
+		// Handlers don't see the shutdown context, so cut off the ones
+		// still running rather than wait on them
+		log.Println("HTTP requests still running after the grace period, closing their connections")
+		if err := srv.Close(); err != nil {
+			log.Println("Error closing HTTP server: ", err)
+		}
+	} else if err != nil {
+		log.Println("Error shutting down HTTP server: ", err)
+	}
+	stopGrpc(shutdownCtx, grpcServer)
+	stopStreams()
+	streams.Wait()
+	if err := eventBus.Close(); err != nil {
+		log.Println("Error closing event bus: ", err)
+	}
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelDrain()
+	if err := workerPool.Shutdown(drainCtx); err != nil {
+		log.Println("Error draining job workers: ", err)
+	}
+	disconnectCtx, cancelDisconnect := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelDisconnect()
+	if err := configs.DB.Disconnect(disconnectCtx); err != nil {
+		log.Println("Error disconnecting from MongoDB: ", err)
+	}
+}
+
+// stopGrpc lets in-flight gRPC calls finish, cutting them off once ctx is
+// done.
+func stopGrpc(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Println("Error shutting down gRPC server: ", ctx.Err())
+		server.Stop()
+	}
+}
+
+func newEventBus() (events.Bus, error) {
+	switch configs.EventBus() {
+	case "local":
+		return events.NewLocalBus(), nil
+	case "nats":
+		log.Println("Publishing events to NATS at ", configs.NatsURL())
+		return events.NewNATSBus(configs.NatsURL(), configs.NatsSubjectPrefix())
+	default:
+		return nil, fmt.Errorf("unknown event bus '%s'", configs.EventBus())
+	}
 }
 
 func corsMiddleware() gin.HandlerFunc {
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// AuthorProfile is what the author has chosen to show publicly about
+// themselves. DisplayName is copied from their Keycloak username whenever
+// they use their own profile, since other users' tokens can't look it up.
+type AuthorProfile struct {
+	DisplayName string     `bson:"displayName,omitempty" json:"displayName,omitempty"`
+	Bio         string     `bson:"bio,omitempty" json:"bio,omitempty"`
+	AvatarUrl   string     `bson:"avatarUrl,omitempty" json:"avatarUrl,omitempty"`
+	UpdatedAt   *time.Time `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
+
+// AuthorProfileUpdate holds the fields the owner can edit.
+type AuthorProfileUpdate struct {
+	Bio       string `json:"bio"`
+	AvatarUrl string `json:"avatarUrl"`
+}
+
+// Author is the public view of a user, with stats over their published
+// public recipes.
+type Author struct {
+	Id            string  `json:"id"`
+	DisplayName   string  `json:"displayName,omitempty"`
+	Bio           string  `json:"bio,omitempty"`
+	AvatarUrl     string  `json:"avatarUrl,omitempty"`
+	RecipeCount   int     `json:"recipeCount"`
+	AverageRating float64 `json:"averageRating"`
+	RatingCount   int     `json:"ratingCount"`
+	FollowerCount int     `json:"followerCount"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type CatalogIngredient struct {
+	Id          string   `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string   `bson:"name" json:"name"`
+	Synonyms    []string `bson:"synonyms" json:"synonyms"`
+	PluralForms []string `bson:"pluralForms" json:"pluralForms"`
+	Category    string   `bson:"category,omitempty" json:"category,omitempty"`
+	// DefaultDensity is in grams per millilitre. Nutrition weighs volumes
+	// of the ingredient with it instead of the nutrient database's weight
+	// per cup.
+	DefaultDensity float64   `bson:"defaultDensity,omitempty" json:"defaultDensity,omitempty"`
+	CreatedAt      time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// UnmatchedIngredient is an ingredient line the catalog couldn't map,
+// waiting for someone to add it to the catalog or dismiss it.
+type UnmatchedIngredient struct {
+	Id        string    `bson:"_id" json:"id"`
+	Examples  []string  `bson:"examples" json:"examples"`
+	RecipeIds []string  `bson:"recipeIds" json:"recipeIds"`
+	Count     int       `bson:"count" json:"count"`
+	FirstSeen time.Time `bson:"firstSeen" json:"firstSeen"`
+	LastSeen  time.Time `bson:"lastSeen" json:"lastSeen"`
+}
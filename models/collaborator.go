@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	// CollaboratorEditor can change a recipe's content but not its
+	// visibility, status or collaborators.
+	CollaboratorEditor = "editor"
+	// CollaboratorViewer can see a recipe even while it's private or a draft.
+	CollaboratorViewer = "viewer"
+)
+
+var CollaboratorRoles = []string{CollaboratorEditor, CollaboratorViewer}
+
+type Collaborator struct {
+	UserId  string    `bson:"userId" json:"userId"`
+	Role    string    `bson:"role" json:"role"`
+	AddedAt time.Time `bson:"addedAt" json:"addedAt"`
+}
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+// Invitation asks someone to collaborate on a recipe. Invitee is an email
+// address or Keycloak username, since the invited user's ID isn't known
+// until they accept.
+type Invitation struct {
+	Id          string     `bson:"_id" json:"id"`
+	RecipeId    string     `bson:"recipeId" json:"recipeId"`
+	RecipeTitle string     `bson:"recipeTitle" json:"recipeTitle"`
+	InvitedBy   string     `bson:"invitedBy" json:"invitedBy"`
+	Invitee     string     `bson:"invitee" json:"invitee"`
+	Role        string     `bson:"role" json:"role"`
+	Status      string     `bson:"status" json:"status"`
+	CreatedAt   time.Time  `bson:"createdAt" json:"createdAt"`
+	RespondedAt *time.Time `bson:"respondedAt,omitempty" json:"respondedAt,omitempty"`
+	AcceptedBy  string     `bson:"acceptedBy,omitempty" json:"acceptedBy,omitempty"`
+}
+
+type InvitationRequest struct {
+	Invitee string `json:"invitee" binding:"required"`
+	Role    string `json:"role" binding:"required"`
+}
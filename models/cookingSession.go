@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// CookingSession tracks a user's progress through a recipe so cooking mode
+// can resume on another device. There's at most one per user and recipe.
+type CookingSession struct {
+	Id       string `bson:"_id" json:"id"`
+	UserId   string `bson:"userId" json:"userId"`
+	RecipeId string `bson:"recipeId" json:"recipeId"`
+	// CurrentStep is an index into the recipe's instructions.
+	CurrentStep int `bson:"currentStep" json:"currentStep"`
+	Servings    int `bson:"servings,omitempty" json:"servings,omitempty"`
+	// CheckedIngredients are indexes into the recipe's ingredients.
+	CheckedIngredients []int     `bson:"checkedIngredients" json:"checkedIngredients"`
+	StartedAt          time.Time `bson:"startedAt" json:"startedAt"`
+	UpdatedAt          time.Time `bson:"updatedAt" json:"updatedAt"`
+	ExpiresAt          time.Time `bson:"expiresAt" json:"expiresAt"`
+	// ScaledIngredients are the recipe's ingredients scaled to Servings,
+	// filled in on responses only.
+	ScaledIngredients []string `bson:"-" json:"scaledIngredients,omitempty"`
+}
+
+// CookingSessionUpdate is a partial update, only the fields sent change.
+type CookingSessionUpdate struct {
+	CurrentStep        *int  `json:"currentStep"`
+	Servings           *int  `json:"servings"`
+	CheckedIngredients []int `json:"checkedIngredients"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	FeedRecipePublished = "recipe.published"
+	FeedRecipeUpdated   = "recipe.updated"
+	FeedReviewCreated   = "review.created"
+)
+
+// FeedEvent is something that happened that shows up in activity feeds.
+// Recipe events reach the actor's followers, review events reach the
+// RecipientId, the author of the reviewed recipe. Sequence is what feed
+// streams resume from, like RecipeEvent's.
+type FeedEvent struct {
+	Id          string    `bson:"_id" json:"id"`
+	Sequence    int64     `bson:"sequence" json:"sequence"`
+	Type        string    `bson:"type" json:"type"`
+	ActorId     string    `bson:"actorId" json:"actorId"`
+	RecipientId string    `bson:"recipientId,omitempty" json:"recipientId,omitempty"`
+	RecipeId    string    `bson:"recipeId" json:"recipeId"`
+	RecipeTitle string    `bson:"recipeTitle" json:"recipeTitle"`
+	Rating      int       `bson:"rating,omitempty" json:"rating,omitempty"`
+	Comment     string    `bson:"comment,omitempty" json:"comment,omitempty"`
+	CreatedAt   time.Time `bson:"createdAt" json:"createdAt"`
+}
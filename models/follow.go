@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type Follow struct {
+	Id         string    `bson:"_id" json:"-"`
+	FollowerId string    `bson:"followerId" json:"followerId"`
+	AuthorId   string    `bson:"authorId" json:"authorId"`
+	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+type Job struct {
+	Id              string                 `bson:"_id,omitempty" json:"id,omitempty"`
+	Type            string                 `bson:"type" json:"type"`
+	Status          string                 `bson:"status" json:"status"`
+	OwnerId         string                 `bson:"ownerId,omitempty" json:"ownerId,omitempty"`
+	Payload         map[string]interface{} `bson:"payload,omitempty" json:"payload,omitempty"`
+	Progress        int                    `bson:"progress" json:"progress"`
+	Result          map[string]interface{} `bson:"result,omitempty" json:"result,omitempty"`
+	Error           string                 `bson:"error,omitempty" json:"error,omitempty"`
+	Attempts        int                    `bson:"attempts" json:"attempts"`
+	MaxAttempts     int                    `bson:"maxAttempts" json:"maxAttempts"`
+	CancelRequested bool                   `bson:"cancelRequested" json:"cancelRequested"`
+	RunAt           time.Time              `bson:"runAt" json:"runAt"`
+	HeartbeatAt     *time.Time             `bson:"heartbeatAt,omitempty" json:"-"`
+	CreatedAt       time.Time              `bson:"createdAt" json:"createdAt"`
+	StartedAt       *time.Time             `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	FinishedAt      *time.Time             `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+	Links           map[string]string      `bson:"-" json:"links,omitempty"`
+}
+
+type JobRequest struct {
+	Type    string                 `json:"type"`
+	Payload map[string]interface{} `json:"payload"`
+}
@@ -2,5 +2,9 @@ package models
 
 type MongoUser struct {
 	Id string `bson:"_id,omitempty" json:"id,omitempty"`
-	FavoriteRecipes []string `json:"favoriteRecipes"`
-}
\ No newline at end of file
+	FavoriteRecipes []string `bson:"favoriteRecipes,omitempty" json:"favoriteRecipes"`
+	Preferences *UserPreferences `bson:"preferences,omitempty" json:"preferences,omitempty"`
+	Pantry []string `bson:"pantry,omitempty" json:"pantry,omitempty"`
+	Profile *AuthorProfile `bson:"profile,omitempty" json:"profile,omitempty"`
+	Notifications *NotificationPreferences `bson:"notifications,omitempty" json:"notifications,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Favorites are added with $addToSet on favoriteRecipes, the field has to
+// decode from there.
+func TestMongoUserFavoritesRoundTrip(t *testing.T) {
+	document := bson.M{"_id": "user"}
+	for _, recipeId := range []string{"first", "second"} {
+		favorites, _ := document["favoriteRecipes"].(bson.A)
+		document["favoriteRecipes"] = append(favorites, recipeId)
+	}
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mongoUser MongoUser
+	if err = bson.Unmarshal(raw, &mongoUser); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(mongoUser.FavoriteRecipes, want) {
+		t.Fatalf("FavoriteRecipes = %v, want %v", mongoUser.FavoriteRecipes, want)
+	}
+
+	raw, err = bson.Marshal(mongoUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bson.Raw(raw).LookupErr("favoriteRecipes"); err != nil {
+		t.Errorf("encoded user has no favoriteRecipes: %v", bson.Raw(raw))
+	}
+}
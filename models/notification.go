@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	NotificationReviewCreated   = "review.created"
+	NotificationRecipeForked    = "recipe.forked"
+	NotificationRecipeFavorited = "recipe.favorited"
+)
+
+// Notification tells a user that someone did something with one of their
+// recipes. Every notification lands in the recipient's inbox, and is also
+// delivered through the channels they turned on in their
+// NotificationPreferences. ForkId is the new recipe for recipe.forked
+// notifications.
+type Notification struct {
+	Id          string     `bson:"_id" json:"id"`
+	Type        string     `bson:"type" json:"type"`
+	RecipientId string     `bson:"recipientId" json:"recipientId"`
+	ActorId     string     `bson:"actorId" json:"actorId"`
+	ActorName   string     `bson:"actorName,omitempty" json:"actorName,omitempty"`
+	RecipeId    string     `bson:"recipeId" json:"recipeId"`
+	RecipeTitle string     `bson:"recipeTitle" json:"recipeTitle"`
+	ForkId      string     `bson:"forkId,omitempty" json:"forkId,omitempty"`
+	Rating      int        `bson:"rating,omitempty" json:"rating,omitempty"`
+	Comment     string     `bson:"comment,omitempty" json:"comment,omitempty"`
+	Read        bool       `bson:"read" json:"read"`
+	ReadAt      *time.Time `bson:"readAt,omitempty" json:"readAt,omitempty"`
+	CreatedAt   time.Time  `bson:"createdAt" json:"createdAt"`
+	// DeliveredTo lists the channels that already sent the notification, so
+	// retrying a delivery doesn't send it twice.
+	DeliveredTo []string `bson:"deliveredTo,omitempty" json:"-"`
+}
+
+// NotificationPreferences picks the channels notifications go out through
+// besides the inbox, and where they are sent. WebhookSecret signs webhook
+// notifications, it is made by the server when WebhookUrl is set.
+type NotificationPreferences struct {
+	Channels      []string `bson:"channels" json:"channels"`
+	Email         string   `bson:"email,omitempty" json:"email,omitempty"`
+	WebhookUrl    string   `bson:"webhookUrl,omitempty" json:"webhookUrl,omitempty"`
+	WebhookSecret string   `bson:"webhookSecret,omitempty" json:"webhookSecret,omitempty"`
+}
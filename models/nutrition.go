@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	NutritionConfidenceHigh      = "high"
+	NutritionConfidenceEstimated = "estimated"
+	NutritionConfidenceUnmatched = "unmatched"
+)
+
+// NutritionFacts amounts are in kcal for Calories, milligrams for Sodium and
+// grams for everything else.
+type NutritionFacts struct {
+	Calories      float64 `bson:"calories" json:"calories"`
+	Protein       float64 `bson:"protein" json:"protein"`
+	Fat           float64 `bson:"fat" json:"fat"`
+	Carbohydrates float64 `bson:"carbohydrates" json:"carbohydrates"`
+	Fiber         float64 `bson:"fiber" json:"fiber"`
+	Sugar         float64 `bson:"sugar" json:"sugar"`
+	Sodium        float64 `bson:"sodium" json:"sodium"`
+}
+
+type IngredientNutrition struct {
+	Ingredient  string  `bson:"ingredient" json:"ingredient"`
+	MatchedFood string  `bson:"matchedFood,omitempty" json:"matchedFood,omitempty"`
+	Grams       float64 `bson:"grams,omitempty" json:"grams,omitempty"`
+	Confidence  string  `bson:"confidence" json:"confidence"`
+}
+
+type Nutrition struct {
+	Total        NutritionFacts        `bson:"total" json:"total"`
+	PerServing   NutritionFacts        `bson:"perServing" json:"perServing"`
+	Servings     int                   `bson:"servings" json:"servings"`
+	Confidence   float64               `bson:"confidence" json:"confidence"`
+	Ingredients  []IngredientNutrition `bson:"ingredients" json:"ingredients"`
+	CalculatedAt time.Time             `bson:"calculatedAt" json:"calculatedAt"`
+}
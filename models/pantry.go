@@ -0,0 +1,13 @@
+package models
+
+type Pantry struct {
+	Ingredients []string `json:"ingredients"`
+}
+
+type PantryMatch struct {
+	Recipe             Recipe   `json:"recipe"`
+	CoveredIngredients int      `json:"coveredIngredients"`
+	TotalIngredients   int      `json:"totalIngredients"`
+	Coverage           float64  `json:"coverage"`
+	MissingIngredients []string `json:"missingIngredients"`
+}
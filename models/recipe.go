@@ -1,10 +1,82 @@
 package models
 
+import "time"
+
 type Recipe struct {
-	Id           string   `bson:"_id,omitempty" json:"id,omitempty"`
-	Title        string   `json:"title,omitempty"`
-	Ingredients  []string `json:"ingredients,omitempty"`
-	Instructions []string `json:"instructions,omitempty"`
-	AuthorId     string   `bson:"authorId,omitempty" json:"authorId,omitempty"`
-	ImageLinks   string   `bson:"imageLinks,omitempty" json:"imageLinks,omitempty"`
+	Id                  string     `bson:"_id,omitempty" json:"id,omitempty"`
+	Title               string     `json:"title,omitempty"`
+	Ingredients         []string   `json:"ingredients,omitempty"`
+	Instructions        []Step     `json:"instructions,omitempty"`
+	AuthorId            string     `bson:"authorId,omitempty" json:"authorId,omitempty"`
+	ImageLinks          string     `bson:"imageLinks,omitempty" json:"imageLinks,omitempty"`
+	Servings            int        `bson:"servings,omitempty" json:"servings,omitempty"`
+	Nutrition           *Nutrition `bson:"nutrition,omitempty" json:"nutrition,omitempty"`
+	DietaryTags         []string   `bson:"dietaryTags,omitempty" json:"dietaryTags,omitempty"`
+	DetectedDietaryTags []string   `bson:"detectedDietaryTags,omitempty" json:"detectedDietaryTags,omitempty"`
+	Allergens           []string   `bson:"allergens,omitempty" json:"allergens,omitempty"`
+	Cuisine             string     `bson:"cuisine,omitempty" json:"cuisine,omitempty"`
+	Course              string     `bson:"course,omitempty" json:"course,omitempty"`
+	Difficulty          string     `bson:"difficulty,omitempty" json:"difficulty,omitempty"`
+	Yield               *Yield     `bson:"yield,omitempty" json:"yield,omitempty"`
+	// PrepTime, CookTime and TotalTime are ISO 8601 durations, e.g. "PT45M".
+	PrepTime  string `bson:"prepTime,omitempty" json:"prepTime,omitempty"`
+	CookTime  string `bson:"cookTime,omitempty" json:"cookTime,omitempty"`
+	TotalTime string `bson:"totalTime,omitempty" json:"totalTime,omitempty"`
+	// TotalTimeMinutes is derived from TotalTime for filtering.
+	TotalTimeMinutes int `bson:"totalTimeMinutes,omitempty" json:"totalTimeMinutes,omitempty"`
+	// AverageRating and RatingCount are maintained by the API, any values
+	// sent by clients are ignored.
+	AverageRating float64 `bson:"averageRating,omitempty" json:"averageRating,omitempty"`
+	RatingCount   int     `bson:"ratingCount,omitempty" json:"ratingCount,omitempty"`
+	// ParentRecipeId and ParentAuthorId are set on forks. The author is kept
+	// so attribution survives the parent being deleted.
+	ParentRecipeId string `bson:"parentRecipeId,omitempty" json:"parentRecipeId,omitempty"`
+	ParentAuthorId string `bson:"parentAuthorId,omitempty" json:"parentAuthorId,omitempty"`
+	// Visibility is one of the Visibility constants. Recipes saved before it
+	// existed have none and are treated as public.
+	Visibility string `bson:"visibility,omitempty" json:"visibility,omitempty"`
+	// Status is one of the Status constants and only changes through the
+	// publish and archive endpoints. Recipes saved before it existed have
+	// none and are treated as published.
+	Status      string     `bson:"status,omitempty" json:"status,omitempty"`
+	PublishedAt *time.Time `bson:"publishedAt,omitempty" json:"publishedAt,omitempty"`
+	ArchivedAt  *time.Time `bson:"archivedAt,omitempty" json:"archivedAt,omitempty"`
+	// Collaborators are managed through invitations, not recipe updates.
+	Collaborators []Collaborator `bson:"collaborators,omitempty" json:"collaborators,omitempty"`
 }
+
+const (
+	// VisibilityPrivate recipes are only visible to their author.
+	VisibilityPrivate = "private"
+	// VisibilityUnlisted recipes can be fetched by anyone with the ID but
+	// don't show up in listings, search or recommendations.
+	VisibilityUnlisted = "unlisted"
+	VisibilityPublic   = "public"
+)
+
+var Visibilities = []string{VisibilityPrivate, VisibilityUnlisted, VisibilityPublic}
+
+const (
+	// StatusDraft recipes are only visible to their author.
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	// StatusArchived recipes can still be opened, e.g. from favorites, but
+	// don't show up in listings, search or recommendations.
+	StatusArchived = "archived"
+)
+
+// Yield is what a recipe makes, e.g. 24 cookies or 2 loaves.
+type Yield struct {
+	Quantity float64 `bson:"quantity" json:"quantity"`
+	Unit     string  `bson:"unit" json:"unit"`
+}
+
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+var Difficulties = []string{DifficultyEasy, DifficultyMedium, DifficultyHard}
+
+var Courses = []string{"appetizer", "breakfast", "bread", "dessert", "drink", "main", "salad", "sauce", "side", "snack", "soup"}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	RecipeEventUpdate = "update"
+	RecipeEventReview = "review"
+)
+
+// RecipeEvent is a change to a recipe pushed to everyone watching it live.
+// Update events carry the recipe as it is now, review events the review.
+// Sequence orders events across every copy of the API and is the id streams
+// hand out and resume from.
+type RecipeEvent struct {
+	Id        string    `bson:"_id" json:"id"`
+	Sequence  int64     `bson:"sequence" json:"sequence"`
+	RecipeId  string    `bson:"recipeId" json:"recipeId"`
+	Type      string    `bson:"type" json:"type"`
+	ActorId   string    `bson:"actorId,omitempty" json:"actorId,omitempty"`
+	Recipe    *Recipe   `bson:"recipe,omitempty" json:"recipe,omitempty"`
+	Review    *Review   `bson:"review,omitempty" json:"review,omitempty"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
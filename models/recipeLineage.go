@@ -0,0 +1,38 @@
+package models
+
+// RecipeLineage is where a recipe came from and what's been made from it.
+type RecipeLineage struct {
+	// Ancestors run from the original recipe down to the direct parent.
+	Ancestors []Recipe `json:"ancestors"`
+	Forks     []Recipe `json:"forks"`
+	// DescendantCount includes forks of forks, but only those the viewer
+	// can open.
+	DescendantCount int `json:"descendantCount"`
+}
+
+const (
+	DiffUnchanged = "="
+	DiffAdded     = "+"
+	DiffRemoved   = "-"
+)
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// RecipeDiff is what a fork changed compared to the recipe it was forked
+// from. Ingredients and Instructions are line diffs, every other changed
+// field is listed in Fields.
+type RecipeDiff struct {
+	RecipeId     string                 `json:"recipeId"`
+	BaseRecipeId string                 `json:"baseRecipeId"`
+	Fields       map[string]FieldChange `json:"fields"`
+	Ingredients  []DiffLine             `json:"ingredients"`
+	Instructions []DiffLine             `json:"instructions"`
+}
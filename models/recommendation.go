@@ -0,0 +1,7 @@
+package models
+
+type Recommendation struct {
+	Recipe  Recipe   `json:"recipe"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
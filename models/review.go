@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Review is a user's rating of a recipe, at most one per user and recipe.
+type Review struct {
+	Id        string    `bson:"_id" json:"id"`
+	RecipeId  string    `bson:"recipeId" json:"recipeId"`
+	UserId    string    `bson:"userId" json:"userId"`
+	Rating    int       `bson:"rating" json:"rating"`
+	Comment   string    `bson:"comment,omitempty" json:"comment,omitempty"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+type ReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required"`
+	Comment string `json:"comment"`
+}
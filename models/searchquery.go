@@ -2,4 +2,19 @@ package models
 
 type SearchQuery struct {
 	SearchTerm string `json:"searchTerm"`
-}
\ No newline at end of file
+	IncludeIngredients []string `json:"includeIngredients"`
+	ExcludeIngredients []string `json:"excludeIngredients"`
+	MaxTotalTime int `json:"maxTotalTime"`
+	Cuisine string `json:"cuisine"`
+	Course string `json:"course"`
+	Difficulty string `json:"difficulty"`
+	DietaryTags []string `json:"dietaryTags"`
+	ExcludeAllergens []string `json:"excludeAllergens"`
+	MinRating float64 `json:"minRating"`
+	AuthorId string `json:"authorId"`
+}
+
+type FacetValue struct {
+	Value string `bson:"_id" json:"value"`
+	Count int `bson:"count" json:"count"`
+}
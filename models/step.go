@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Step is one instruction. Recipes saved before steps were structured stored
+// plain strings, so a step decodes from either a string or an object.
+type Step struct {
+	// Section optionally starts a new group of steps, e.g. "For the sauce".
+	Section string `bson:"section,omitempty" json:"section,omitempty"`
+	Text    string `bson:"text" json:"text"`
+	// Timer is an ISO 8601 duration set by the author.
+	Timer     string `bson:"timer,omitempty" json:"timer,omitempty"`
+	ImageLink string `bson:"imageLink,omitempty" json:"imageLink,omitempty"`
+	// Ingredients are indexes into the recipe's ingredients.
+	Ingredients []int `bson:"ingredients,omitempty" json:"ingredients,omitempty"`
+	// DetectedTimers are durations found in Text, maintained by the API.
+	DetectedTimers []StepTimer `bson:"detectedTimers,omitempty" json:"detectedTimers,omitempty"`
+}
+
+// StepTimer is a duration mentioned in a step, e.g. "bake 20-25 minutes" is
+// PT20M with a MaxDuration of PT25M.
+type StepTimer struct {
+	Text        string `bson:"text" json:"text"`
+	Duration    string `bson:"duration" json:"duration"`
+	MaxDuration string `bson:"maxDuration,omitempty" json:"maxDuration,omitempty"`
+}
+
+// step has Step's fields without its decoding methods.
+type step Step
+
+func (s *Step) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*s = Step{Text: text}
+		return nil
+	}
+	return json.Unmarshal(data, (*step)(s))
+}
+
+func (s *Step) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bsontype.String {
+		text, ok := bson.RawValue{Type: t, Value: data}.StringValueOK()
+		if !ok {
+			return errors.New("invalid step text")
+		}
+		*s = Step{Text: text}
+		return nil
+	}
+	var decoded step
+	if err := bson.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*s = Step(decoded)
+	return nil
+}
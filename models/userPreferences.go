@@ -0,0 +1,17 @@
+package models
+
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+type UserPreferences struct {
+	Diets               []string `bson:"diets" json:"diets"`
+	Allergens           []string `bson:"allergens" json:"allergens"`
+	DislikedIngredients []string `bson:"dislikedIngredients" json:"dislikedIngredients"`
+	PreferredUnits      string   `bson:"preferredUnits,omitempty" json:"preferredUnits,omitempty"`
+	DefaultServings     int      `bson:"defaultServings,omitempty" json:"defaultServings,omitempty"`
+	// ApplyToListings opts the user in to having listings, search and
+	// recommendations filtered by the preferences above.
+	ApplyToListings bool `bson:"applyToListings" json:"applyToListings"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+const (
+	WebhookRecipeCreated = "recipe.created"
+	WebhookRecipeUpdated = "recipe.updated"
+	WebhookRecipeDeleted = "recipe.deleted"
+	WebhookFavoriteAdded = "favorite.added"
+)
+
+var WebhookEvents = []string{WebhookRecipeCreated, WebhookRecipeUpdated, WebhookRecipeDeleted, WebhookFavoriteAdded}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookSubscription sends the events it lists to Url. Secret signs every
+// delivery and is only shown when the subscription is created.
+type WebhookSubscription struct {
+	Id          string    `bson:"_id" json:"id"`
+	Url         string    `bson:"url" json:"url"`
+	Events      []string  `bson:"events" json:"events"`
+	Description string    `bson:"description,omitempty" json:"description,omitempty"`
+	Active      bool      `bson:"active" json:"active"`
+	Secret      string    `bson:"secret" json:"secret,omitempty"`
+	CreatedBy   string    `bson:"createdBy" json:"createdBy"`
+	CreatedAt   time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+type WebhookSubscriptionRequest struct {
+	Url         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookEvent is the JSON body posted to subscribers. Id stays the same
+// across retries and redeliveries so subscribers can drop duplicates.
+type WebhookEvent struct {
+	Id        string                 `bson:"id" json:"id"`
+	Type      string                 `bson:"type" json:"type"`
+	CreatedAt time.Time              `bson:"createdAt" json:"createdAt"`
+	Data      map[string]interface{} `bson:"data" json:"data"`
+}
+
+// WebhookDelivery is the log of sending one event to one subscription.
+type WebhookDelivery struct {
+	Id             string           `bson:"_id" json:"id"`
+	SubscriptionId string           `bson:"subscriptionId" json:"subscriptionId"`
+	Event          WebhookEvent     `bson:"event" json:"event"`
+	Status         string           `bson:"status" json:"status"`
+	Attempts       []WebhookAttempt `bson:"attempts" json:"attempts"`
+	CreatedAt      time.Time        `bson:"createdAt" json:"createdAt"`
+	DeliveredAt    *time.Time       `bson:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+}
+
+type WebhookAttempt struct {
+	At         time.Time `bson:"at" json:"at"`
+	StatusCode int       `bson:"statusCode,omitempty" json:"statusCode,omitempty"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs int64     `bson:"durationMs" json:"durationMs"`
+}
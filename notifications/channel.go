@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hopk8412/table-recipes-api/models"
+)
+
+// Channel delivers notifications outside the in-app inbox.
+type Channel interface {
+	// Name is what users list in their preferences to turn the channel on.
+	Name() string
+	// Validate checks the preferences have what the channel needs to reach
+	// the user.
+	Validate(preferences models.NotificationPreferences) error
+	Send(ctx context.Context, preferences models.NotificationPreferences, notification models.Notification) error
+}
+
+var ErrUnknownChannel = errors.New("unknown notification channel")
+
+var channels = map[string]Channel{}
+
+// Register makes a channel available to users. It is meant to be called
+// during startup, channels that aren't configured are just never registered.
+func Register(channel Channel) {
+	channels[channel.Name()] = channel
+}
+
+func Lookup(name string) (Channel, error) {
+	channel, ok := channels[name]
+	if !ok {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownChannel, name)
+	}
+	return channel, nil
+}
+
+// Names lists the registered channels in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render writes a notification out as a subject line and a plain text body.
+func Render(notification models.Notification) (string, string) {
+	actor := notification.ActorName
+	if actor == "" {
+		actor = "Someone"
+	}
+	switch notification.Type {
+	case models.NotificationReviewCreated:
+		subject := fmt.Sprintf("%s rated %s %d/5", actor, notification.RecipeTitle, notification.Rating)
+		if notification.Comment == "" {
+			return subject, subject + "."
+		}
+		return subject, fmt.Sprintf("%s and said:\n\n%s", subject, notification.Comment)
+	case models.NotificationRecipeForked:
+		subject := fmt.Sprintf("%s forked %s", actor, notification.RecipeTitle)
+		return subject, subject + " to make their own version of it."
+	case models.NotificationRecipeFavorited:
+		subject := fmt.Sprintf("%s favorited %s", actor, notification.RecipeTitle)
+		return subject, subject + "."
+	default:
+		subject := fmt.Sprintf("New activity on %s", notification.RecipeTitle)
+		return subject, subject + "."
+	}
+}
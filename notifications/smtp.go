@@ -0,0 +1,81 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/configs"
+	"github.com/hopk8412/table-recipes-api/models"
+)
+
+const EmailChannel = "email"
+
+type smtpChannel struct {
+	config configs.SMTPSettings
+}
+
+func NewSMTPChannel(config configs.SMTPSettings) Channel {
+	return &smtpChannel{config: config}
+}
+
+func (s *smtpChannel) Name() string {
+	return EmailChannel
+}
+
+func (s *smtpChannel) Validate(preferences models.NotificationPreferences) error {
+	if preferences.Email == "" {
+		return errors.New("an email address is needed for email notifications")
+	}
+	if _, err := mail.ParseAddress(preferences.Email); err != nil {
+		return errors.New("email must be a valid email address")
+	}
+	return nil
+}
+
+func (s *smtpChannel) Send(ctx context.Context, preferences models.NotificationPreferences, notification models.Notification) error {
+	if err := s.Validate(preferences); err != nil {
+		return err
+	}
+	to, err := mail.ParseAddress(preferences.Email)
+	if err != nil {
+		return err
+	}
+	subject, body := Render(notification)
+	message := strings.Join([]string{
+		"From: " + s.config.From,
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + time.Now().UTC().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		body,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+	// smtp.SendMail can't be cancelled, so run it aside and stop waiting when
+	// the context ends.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(s.config.Host, s.config.Port), auth, s.config.From, []string{to.Address}, []byte(message))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("sending email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
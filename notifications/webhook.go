@@ -0,0 +1,124 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/webhooks"
+)
+
+const WebhookChannel = "webhook"
+
+var errPrivateAddress = errors.New("webhookUrl must not point at a private, loopback or link-local address")
+
+// carrierGradeNat is the shared address space (RFC 6598), which net.IP
+// doesn't count as private.
+var carrierGradeNat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+type webhookChannel struct {
+	client *http.Client
+}
+
+// NewWebhookChannel posts notifications as JSON to the URL in each user's
+// preferences, signed with their secret the same way webhooks.Send signs
+// deliveries. Users pick the URL, so it may only reach public addresses.
+func NewWebhookChannel() Channel {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		// Checked on the address actually dialed, so a host that resolves
+		// to something else than it did in Validate, or a redirect, can't
+		// reach the internal network either
+		Control: func(network, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicAddress(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	return &webhookChannel{client: &http.Client{Transport: transport, Timeout: 10 * time.Second}}
+}
+
+func (w *webhookChannel) Name() string {
+	return WebhookChannel
+}
+
+func (w *webhookChannel) Validate(preferences models.NotificationPreferences) error {
+	if preferences.WebhookUrl == "" {
+		return errors.New("a webhookUrl is needed for webhook notifications")
+	}
+	webhookUrl, err := url.Parse(preferences.WebhookUrl)
+	if err != nil || (webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https") || webhookUrl.Host == "" {
+		return errors.New("webhookUrl must be an http or https URL")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, webhookUrl.Hostname())
+	if err != nil || len(addresses) == 0 {
+		return errors.New("webhookUrl host " + webhookUrl.Hostname() + " can't be resolved")
+	}
+	for _, address := range addresses {
+		if !publicAddress(address.IP) {
+			return errPrivateAddress
+		}
+	}
+	if preferences.WebhookSecret == "" {
+		return errors.New("webhook notifications need a webhookSecret, save the notification preferences again to get one")
+	}
+	return nil
+}
+
+func (w *webhookChannel) Send(ctx context.Context, preferences models.NotificationPreferences, notification models.Notification) error {
+	if err := w.Validate(preferences); err != nil {
+		return err
+	}
+	subject, _ := Render(notification)
+	body, err := json.Marshal(map[string]interface{}{"summary": subject, "notification": notification})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, preferences.WebhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.HeaderEvent, notification.Type)
+	req.Header.Set(webhooks.HeaderEventId, notification.Id)
+	req.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhooks.HeaderSignature, webhooks.Sign(preferences.WebhookSecret, timestamp, body))
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// publicAddress reports whether ip is routable on the internet, rather than
+// one of the server's own or its network's addresses.
+func publicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !carrierGradeNat.Contains(ip)
+}
@@ -0,0 +1,178 @@
+package nutrition
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/ingredients"
+	"github.com/hopk8412/table-recipes-api/models"
+)
+
+// foods.json is a hand picked subset of USDA FoodData Central (SR Legacy),
+// with nutrients per 100g plus the weights needed to convert common kitchen
+// measures to grams.
+//
+//go:embed data/foods.json
+var foodsData []byte
+
+type food struct {
+	Name         string                `json:"name"`
+	Synonyms     []string              `json:"synonyms"`
+	Per100g      models.NutritionFacts `json:"per100g"`
+	GramsPerCup  float64               `json:"gramsPerCup"`
+	GramsPerUnit map[string]float64    `json:"gramsPerUnit"`
+}
+
+type foodName struct {
+	pattern *regexp.Regexp
+	length  int
+	food    *food
+}
+
+var foodNames []foodName
+
+func init() {
+	var foods []food
+	if err := json.Unmarshal(foodsData, &foods); err != nil {
+		log.Fatal("invalid nutrient database: ", err)
+	}
+	for i := range foods {
+		for _, name := range append([]string{foods[i].Name}, foods[i].Synonyms...) {
+			foodNames = append(foodNames, foodName{
+				pattern: regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`),
+				length:  len(name),
+				food:    &foods[i],
+			})
+		}
+	}
+}
+
+const millilitresPerCup = 236.6
+
+var gramsPerMassUnit = map[string]float64{"g": 1, "kg": 1000, "oz": 28.35, "lb": 453.6}
+var cupsPerVolumeUnit = map[string]float64{"cup": 1, "tbsp": 1.0 / 16, "tsp": 1.0 / 48, "ml": 1 / millilitresPerCup, "l": 1000 / millilitresPerCup, "pinch": 1.0 / 768}
+
+// Calculate estimates the nutrition of a recipe from its ingredient lines.
+// Lines that can't be matched to the nutrient database are left out of the
+// totals, and both they and lines whose amount had to be guessed lower the
+// overall confidence.
+func Calculate(ingredientLines []string, servings int) *models.Nutrition {
+	if servings < 1 {
+		servings = 1
+	}
+	result := models.Nutrition{
+		Servings:     servings,
+		Ingredients:  []models.IngredientNutrition{},
+		CalculatedAt: time.Now().UTC(),
+	}
+	score := 0.0
+	for _, raw := range ingredientLines {
+		line := ingredients.ParseLine(raw)
+		detail := models.IngredientNutrition{Ingredient: raw, Confidence: models.NutritionConfidenceUnmatched}
+		matched := match(line.Name)
+		entry := ingredients.LookupCatalog(raw)
+		if matched == nil && entry != nil {
+			// The catalog knows the line under another name, try that.
+			matched = match(strings.ToLower(entry.Name))
+		}
+		if matched != nil {
+			detail.MatchedFood = matched.Name
+			density := 0.0
+			if entry != nil {
+				density = entry.DefaultDensity
+			}
+			grams, exact := toGrams(line, matched, density)
+			detail.Grams = round(grams)
+			addScaled(&result.Total, matched.Per100g, grams/100)
+			if exact {
+				detail.Confidence = models.NutritionConfidenceHigh
+				score += 1
+			} else {
+				detail.Confidence = models.NutritionConfidenceEstimated
+				score += 0.5
+			}
+		}
+		result.Ingredients = append(result.Ingredients, detail)
+	}
+	if len(ingredientLines) > 0 {
+		result.Confidence = round(score / float64(len(ingredientLines)))
+	}
+	addScaled(&result.PerServing, result.Total, 1/float64(servings))
+	roundFacts(&result.Total)
+	roundFacts(&result.PerServing)
+	return &result
+}
+
+// match returns the food whose name or synonym is the longest whole-word
+// match in the ingredient name, so "chicken breast" wins over "chicken".
+func match(name string) *food {
+	var best *foodName
+	for i := range foodNames {
+		if foodNames[i].pattern.MatchString(name) && (best == nil || foodNames[i].length > best.length) {
+			best = &foodNames[i]
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.food
+}
+
+// toGrams converts the line's amount to grams. Volumes are weighed with the
+// catalog entry's density, in grams per millilitre, when it has one: it was
+// set for this very ingredient, while the matched food may only be the
+// closest one in the database ("almond flour" matches "flour"). The second
+// return value is false when the amount had to be guessed, e.g. "salt to
+// taste" counts as nothing at all.
+func toGrams(line ingredients.Line, matched *food, density float64) (float64, bool) {
+	if line.Quantity == 0 {
+		return 0, false
+	}
+	if grams, ok := gramsPerMassUnit[line.Unit]; ok {
+		return line.Quantity * grams, true
+	}
+	if cups, ok := cupsPerVolumeUnit[line.Unit]; ok {
+		if density > 0 {
+			return line.Quantity * cups * millilitresPerCup * density, true
+		}
+		return line.Quantity * cups * matched.GramsPerCup, true
+	}
+	if grams, ok := matched.GramsPerUnit[line.Unit]; ok {
+		return line.Quantity * grams, true
+	}
+	// A unit the food has no weight for ("2 slices onion"), fall back to a
+	// whole item if we know what one weighs.
+	if grams, ok := matched.GramsPerUnit["each"]; ok {
+		return line.Quantity * grams, false
+	}
+	return 0, false
+}
+
+func addScaled(total *models.NutritionFacts, per100g models.NutritionFacts, factor float64) {
+	total.Calories += per100g.Calories * factor
+	total.Protein += per100g.Protein * factor
+	total.Fat += per100g.Fat * factor
+	total.Carbohydrates += per100g.Carbohydrates * factor
+	total.Fiber += per100g.Fiber * factor
+	total.Sugar += per100g.Sugar * factor
+	total.Sodium += per100g.Sodium * factor
+}
+
+func roundFacts(facts *models.NutritionFacts) {
+	facts.Calories = math.Round(facts.Calories)
+	facts.Protein = round(facts.Protein)
+	facts.Fat = round(facts.Fat)
+	facts.Carbohydrates = round(facts.Carbohydrates)
+	facts.Fiber = round(facts.Fiber)
+	facts.Sugar = round(facts.Sugar)
+	facts.Sodium = math.Round(facts.Sodium)
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
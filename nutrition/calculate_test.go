@@ -0,0 +1,87 @@
+package nutrition
+
+import (
+	"testing"
+
+	"github.com/hopk8412/table-recipes-api/ingredients"
+	"github.com/hopk8412/table-recipes-api/models"
+)
+
+func TestCalculate(t *testing.T) {
+	nutrition := Calculate([]string{"2 eggs", "1 tbsp butter", "salt to taste", "1 cup unobtainium"}, 2)
+
+	if nutrition.Servings != 2 {
+		t.Errorf("Servings = %d, want 2", nutrition.Servings)
+	}
+	want := []struct {
+		food       string
+		grams      float64
+		confidence string
+	}{
+		{"egg", 100, models.NutritionConfidenceHigh},
+		{"butter", 14.19, models.NutritionConfidenceHigh},
+		{"salt", 0, models.NutritionConfidenceEstimated},
+		{"", 0, models.NutritionConfidenceUnmatched},
+	}
+	if len(nutrition.Ingredients) != len(want) {
+		t.Fatalf("got %d ingredients, want %d", len(nutrition.Ingredients), len(want))
+	}
+	for i, w := range want {
+		got := nutrition.Ingredients[i]
+		if got.MatchedFood != w.food || got.Grams != w.grams || got.Confidence != w.confidence {
+			t.Errorf("ingredient %q = %q %vg %s, want %q %vg %s", got.Ingredient, got.MatchedFood, got.Grams, got.Confidence, w.food, w.grams, w.confidence)
+		}
+	}
+	// 100g of egg and 14.19g of butter
+	if nutrition.Total.Calories != 245 {
+		t.Errorf("Total.Calories = %v, want 245", nutrition.Total.Calories)
+	}
+	if nutrition.PerServing.Calories != 122 {
+		t.Errorf("PerServing.Calories = %v, want 122", nutrition.PerServing.Calories)
+	}
+	// Two exact amounts and a guessed one out of four lines
+	if nutrition.Confidence != 0.63 {
+		t.Errorf("Confidence = %v, want 0.63", nutrition.Confidence)
+	}
+}
+
+func TestCalculateUsesCatalogDensity(t *testing.T) {
+	ingredients.SetCatalog(ingredients.NewCatalog([]models.CatalogIngredient{{Name: "almond flour", DefaultDensity: 0.4}}))
+	defer ingredients.SetCatalog(nil)
+
+	nutrition := Calculate([]string{"1 cup almond flour", "1 cup flour"}, 1)
+	// 236.6ml at 0.4g/ml, and the database's 125g per cup without a density
+	for i, want := range []float64{94.64, 125} {
+		if got := nutrition.Ingredients[i]; got.Grams != want || got.Confidence != models.NutritionConfidenceHigh {
+			t.Errorf("ingredient %q = %vg %s, want %vg high", got.Ingredient, got.Grams, got.Confidence, want)
+		}
+	}
+}
+
+func TestCalculateDefaultsServings(t *testing.T) {
+	for _, servings := range []int{0, -3} {
+		if got := Calculate(nil, servings); got.Servings != 1 || got.Confidence != 0 {
+			t.Errorf("Calculate(nil, %d) = %d servings, confidence %v, want 1 serving, confidence 0", servings, got.Servings, got.Confidence)
+		}
+	}
+}
+
+func TestMatchPrefersLongestName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"whole wheat flour", "whole wheat flour"},
+		{"flour", "all-purpose flour"},
+		{"unsalted butter", "butter"},
+	}
+	for _, test := range tests {
+		got := match(test.name)
+		if got == nil || got.Name != test.want {
+			t.Errorf("match(%q) = %v, want %q", test.name, got, test.want)
+		}
+	}
+	if got := match("unobtainium"); got != nil {
+		t.Errorf("match(%q) = %q, want nil", "unobtainium", got.Name)
+	}
+}
@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/hopk8412/table-recipes-api/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const prefix = "/api/v1"
+
+var applyPreferencesParam = queryParam{name: "applyPreferences", kind: "boolean", description: "Pass false to skip the caller's saved preferences, which are applied if they opted in."}
+
+// dietaryDictionary is what GetDietaryDictionary returns.
+type dietaryDictionary struct {
+	DietaryTags []string `json:"dietaryTags"`
+	Allergens   []string `json:"allergens"`
+}
+
+// graphqlParams and graphqlResult are the body and response of the GraphQL
+// endpoint.
+type graphqlParams struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type graphqlResult struct {
+	Data   map[string]interface{}   `json:"data"`
+	Errors []map[string]interface{} `json:"errors,omitempty"`
+}
+
+// routes lists every route main.go registers under /api/v1. Keep it in the
+// same order, spec_test.go checks nothing is missing.
+var routes = []route{
+	{method: http.MethodGet, path: prefix + "/recipes", handler: "GetAllRecipes", tag: "recipes", summary: "List recipes", auth: authOptional,
+		query: []queryParam{
+			{name: "dietaryTags", description: "Comma-separated dietary tags every recipe must have."},
+			{name: "excludeAllergens", description: "Comma-separated allergens no recipe may contain."},
+			applyPreferencesParam,
+		},
+		data: []models.Recipe{}},
+	{method: http.MethodGet, path: prefix + "/recipes/:id", handler: "GetRecipeById", tag: "recipes", summary: "Get a recipe", auth: authOptional,
+		data: models.Recipe{}},
+	{method: http.MethodGet, path: prefix + "/recipes/me", handler: "GetRecipesByAuthorId", tag: "recipes", summary: "List the caller's recipes", auth: authUser,
+		data: []models.Recipe{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/recipes", handler: "GetUserFavoriteRecipes", tag: "favorites", summary: "List the caller's favorite recipes", auth: authUser,
+		description: "Favorites belong to the token's user whatever the ID in the path.",
+		data:        []models.Recipe{}},
+	{method: http.MethodPost, path: prefix + "/recipes", handler: "PostRecipe", tag: "recipes", summary: "Create a recipe", auth: authUser,
+		description: "The caller becomes the author, whatever authorId the body gives.",
+		body:        models.Recipe{}, status: http.StatusCreated, data: mongo.InsertOneResult{}},
+	{method: http.MethodPost, path: prefix + "/recipes/search", handler: "SearchForRecipes", tag: "recipes", summary: "Search recipes", auth: authOptional,
+		query: []queryParam{applyPreferencesParam},
+		body:  models.SearchQuery{}, data: []models.Recipe{},
+		extra: map[string]interface{}{"facets": map[string][]models.FacetValue{}}},
+	{method: http.MethodPost, path: prefix + "/users/:id/recipes", handler: "AddOrRemoveRecipeToUserFavorites", tag: "favorites", summary: "Add or remove a favorite recipe", auth: authUser,
+		description: "Answers 201 when this created the user's favorites.",
+		body:        models.UserRecipeOperation{}, alsoStatus: []int{http.StatusCreated}, data: mongo.UpdateResult{}},
+	{method: http.MethodDelete, path: prefix + "/recipes/:id", handler: "DeleteRecipeById", tag: "recipes", summary: "Delete a recipe", auth: authUser,
+		description: "Only the author may delete a recipe.",
+		data:        mongo.DeleteResult{}},
+	{method: http.MethodPut, path: prefix + "/recipes/:id", handler: "UpdateRecipeById", tag: "recipes", summary: "Replace a recipe's content", auth: authUser,
+		description: "The author and editors may edit a recipe, only the author may change its visibility.",
+		body:        models.Recipe{}, data: models.Recipe{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/preferences", handler: "GetUserPreferences", tag: "users", summary: "Get a user's preferences", auth: authSelf,
+		data: models.UserPreferences{}},
+	{method: http.MethodPut, path: prefix + "/users/:id/preferences", handler: "UpdateUserPreferences", tag: "users", summary: "Replace a user's preferences", auth: authSelf,
+		body: models.UserPreferences{}, data: models.UserPreferences{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/pantry", handler: "GetUserPantry", tag: "pantry", summary: "Get a user's pantry", auth: authSelf,
+		data: models.Pantry{}},
+	{method: http.MethodPut, path: prefix + "/users/:id/pantry", handler: "UpdateUserPantry", tag: "pantry", summary: "Replace a user's pantry", auth: authSelf,
+		body: models.Pantry{}, data: models.Pantry{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/pantry/matches", handler: "GetPantryMatches", tag: "pantry", summary: "Find recipes the pantry covers", auth: authSelf,
+		query: []queryParam{
+			limitParam(20, 0),
+			{name: "maxMissing", kind: "integer", description: "Leave out recipes missing more ingredients than this, no limit by default."},
+			applyPreferencesParam,
+		},
+		data: []models.PantryMatch{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/recommendations", handler: "GetUserRecommendations", tag: "users", summary: "Recommend recipes", auth: authSelf,
+		query: []queryParam{limitParam(10, 0), applyPreferencesParam},
+		data:  []models.Recommendation{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/feed", handler: "GetUserFeed", tag: "feed", summary: "Get a user's feed", auth: authSelf,
+		query: []queryParam{
+			limitParam(20, 100),
+			{name: "before", kind: "date-time", description: "Only return events older than this, the nextBefore of the previous page."},
+		},
+		data: []models.FeedEvent{}, extra: map[string]interface{}{"nextBefore": ""}},
+	{method: http.MethodGet, path: prefix + "/users/:id/feed/events", handler: "StreamUserFeed", tag: "feed", summary: "Stream a user's feed", auth: authSelf,
+		description: "Event ids are increasing sequence numbers. Reconnecting with the last one, in Last-Event-ID or lastEventId, sends what was missed.",
+		query:       []queryParam{{name: "lastEventId", description: "Like Last-Event-ID, for clients that can't set headers."}},
+		stream:      true},
+	{method: http.MethodGet, path: prefix + "/users/:id/following", handler: "GetFollowing", tag: "follows", summary: "List the authors a user follows",
+		data: []models.Follow{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/followers", handler: "GetFollowers", tag: "follows", summary: "List an author's followers",
+		data: []models.Follow{}},
+	{method: http.MethodPut, path: prefix + "/users/:id/following/:authorId", handler: "FollowAuthor", tag: "follows", summary: "Follow an author", auth: authSelf,
+		data: models.Follow{}},
+	{method: http.MethodDelete, path: prefix + "/users/:id/following/:authorId", handler: "UnfollowAuthor", tag: "follows", summary: "Unfollow an author", auth: authSelf,
+		data: mongo.DeleteResult{}},
+	{method: http.MethodGet, path: prefix + "/authors/:id", handler: "GetAuthor", tag: "authors", summary: "Get an author and their recipes",
+		query: []queryParam{{name: "page", kind: "integer", description: "Page of recipes, starting at 1."}, limitParam(20, 100)},
+		data:  models.Author{},
+		extra: map[string]interface{}{"recipes": []models.Recipe{}, "page": 0, "totalPages": 0}},
+	{method: http.MethodPut, path: prefix + "/authors/:id", handler: "UpdateAuthorProfile", tag: "authors", summary: "Update an author's profile", auth: authSelf,
+		body: models.AuthorProfileUpdate{}, data: models.AuthorProfile{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/notifications", handler: "GetNotifications", tag: "notifications", summary: "List a user's notifications", auth: authSelf,
+		query: []queryParam{
+			limitParam(20, 100),
+			{name: "before", kind: "date-time", description: "Only return notifications older than this, the nextBefore of the previous page."},
+			{name: "unread", kind: "boolean", description: "Only return unread notifications."},
+		},
+		data: []models.Notification{}, extra: map[string]interface{}{"unreadCount": 0, "nextBefore": ""}},
+	{method: http.MethodPost, path: prefix + "/users/:id/notifications/read", handler: "MarkAllNotificationsRead", tag: "notifications", summary: "Mark all notifications read", auth: authSelf,
+		data: mongo.UpdateResult{}},
+	{method: http.MethodPost, path: prefix + "/users/:id/notifications/:notificationId/read", handler: "MarkNotificationRead", tag: "notifications", summary: "Mark a notification read", auth: authSelf,
+		data: models.Notification{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/notifications/preferences", handler: "GetNotificationPreferences", tag: "notifications", summary: "Get a user's notification preferences", auth: authSelf,
+		data: models.NotificationPreferences{}, extra: map[string]interface{}{"availableChannels": []string{}}},
+	{method: http.MethodPut, path: prefix + "/users/:id/notifications/preferences", handler: "UpdateNotificationPreferences", tag: "notifications", summary: "Replace a user's notification preferences", auth: authSelf,
+		description: "Email only goes to the caller's verified account email. Webhook notifications are signed like webhook deliveries, with the webhookSecret in the response. It changes whenever webhookUrl does.",
+		body:        models.NotificationPreferences{}, data: models.NotificationPreferences{}},
+	{method: http.MethodGet, path: prefix + "/recipes/:id/reviews", handler: "GetRecipeReviews", tag: "reviews", summary: "List a recipe's reviews", auth: authOptional,
+		data: []models.Review{}},
+	{method: http.MethodGet, path: prefix + "/recipes/:id/events", handler: "StreamRecipeEvents", tag: "recipes", summary: "Stream a recipe's updates and reviews", auth: authOptional,
+		description: "Event ids are increasing sequence numbers. Reconnecting with the last one, in Last-Event-ID or lastEventId, sends what was missed.",
+		query:       []queryParam{{name: "lastEventId", description: "Like Last-Event-ID, for clients that can't set headers."}},
+		stream:      true},
+	{method: http.MethodPost, path: prefix + "/recipes/:id/reviews", handler: "PostRecipeReview", tag: "reviews", summary: "Review a recipe", auth: authUser,
+		description: "Reviewing a recipe again replaces the caller's review.",
+		body:        models.ReviewRequest{}, data: models.Review{}},
+	{method: http.MethodDelete, path: prefix + "/recipes/:id/reviews", handler: "DeleteRecipeReview", tag: "reviews", summary: "Delete the caller's review", auth: authUser,
+		data: mongo.DeleteResult{}},
+	{method: http.MethodGet, path: prefix + "/users/:id/sessions", handler: "GetUserCookingSessions", tag: "sessions", summary: "List a user's cooking sessions", auth: authSelf,
+		data: []models.CookingSession{}},
+	{method: http.MethodGet, path: prefix + "/recipes/shared", handler: "GetSharedRecipes", tag: "collaboration", summary: "List recipes shared with the caller", auth: authUser,
+		data: []models.Recipe{}},
+	{method: http.MethodGet, path: prefix + "/recipes/:id/collaborators", handler: "GetRecipeCollaborators", tag: "collaboration", summary: "List a recipe's collaborators", auth: authUser,
+		description: "The author also gets the pending invitations.",
+		data:        []models.Collaborator{}, extra: map[string]interface{}{"invitations": []models.Invitation{}}},
+	{method: http.MethodPut, path: prefix + "/recipes/:id/collaborators/:userId", handler: "UpdateCollaboratorRole", tag: "collaboration", summary: "Change a collaborator's role", auth: authUser,
+		body: struct {
+			Role string `json:"role" binding:"required"`
+		}{},
+		data: mongo.UpdateResult{}},
+	{method: http.MethodDelete, path: prefix + "/recipes/:id/collaborators/:userId", handler: "RemoveCollaborator", tag: "collaboration", summary: "Remove a collaborator", auth: authUser,
+		description: "The author can remove anyone, collaborators can remove themselves.",
+		data:        mongo.UpdateResult{}},
+	{method: http.MethodPost, path: prefix + "/recipes/:id/invitations", handler: "InviteCollaborator", tag: "collaboration", summary: "Invite a collaborator", auth: authUser,
+		body: models.InvitationRequest{}, status: http.StatusCreated, data: models.Invitation{}},
+	{method: http.MethodDelete, path: prefix + "/recipes/:id/invitations/:invitationId", handler: "RevokeInvitation", tag: "collaboration", summary: "Revoke an invitation", auth: authUser,
+		data: models.Invitation{}},
+	{method: http.MethodGet, path: prefix + "/invitations", handler: "GetMyInvitations", tag: "collaboration", summary: "List the caller's pending invitations", auth: authUser,
+		data: []models.Invitation{}},
+	{method: http.MethodPost, path: prefix + "/invitations/:id/accept", handler: "AcceptInvitation", tag: "collaboration", summary: "Accept an invitation", auth: authUser,
+		data: models.Invitation{}},
+	{method: http.MethodPost, path: prefix + "/invitations/:id/decline", handler: "DeclineInvitation", tag: "collaboration", summary: "Decline an invitation", auth: authUser,
+		data: models.Invitation{}},
+	{method: http.MethodPost, path: prefix + "/recipes/:id/publish", handler: "PublishRecipe", tag: "recipes", summary: "Publish a recipe", auth: authUser,
+		description: "Only the author may publish a recipe, optionally changing its visibility.",
+		body: struct {
+			Visibility string `json:"visibility"`
+		}{},
+		bodyOptional: true, data: models.Recipe{}},
+	{method: http.MethodPost, path: prefix + "/recipes/:id/archive", handler: "ArchiveRecipe", tag: "recipes", summary: "Archive a recipe", auth: authUser,
+		description: "Only the author may archive a recipe.",
+		data:        models.Recipe{}},
+	{method: http.MethodPost, path: prefix + "/recipes/:id/fork", handler: "ForkRecipe", tag: "recipes", summary: "Fork a recipe", auth: authUser,
+		body: struct {
+			Title string `json:"title"`
+		}{},
+		bodyOptional: true, status: http.StatusCreated, data: models.Recipe{}},
+	{method: http.MethodGet, path: prefix + "/recipes/:id/lineage", handler: "GetRecipeLineage", tag: "recipes", summary: "Get a recipe's ancestors and forks", auth: authOptional,
+		data: models.RecipeLineage{}},
+	{method: http.MethodGet, path: prefix + "/recipes/:id/diff", handler: "GetRecipeDiff", tag: "recipes", summary: "Compare a recipe with another", auth: authOptional,
+		query: []queryParam{{name: "against", description: "ID of the recipe to compare with, the parent by default."}},
+		data:  models.RecipeDiff{}},
+	{method: http.MethodGet, path: prefix + "/recipes/:id/session", handler: "GetCookingSession", tag: "sessions", summary: "Get the caller's cooking session", auth: authUser,
+		data: models.CookingSession{}},
+	{method: http.MethodPost, path: prefix + "/recipes/:id/session", handler: "StartCookingSession", tag: "sessions", summary: "Start cooking a recipe", auth: authUser,
+		body: models.CookingSessionUpdate{}, bodyOptional: true, data: models.CookingSession{}},
+	{method: http.MethodPut, path: prefix + "/recipes/:id/session", handler: "UpdateCookingSession", tag: "sessions", summary: "Update the caller's cooking session", auth: authUser,
+		body: models.CookingSessionUpdate{}, data: models.CookingSession{}},
+	{method: http.MethodDelete, path: prefix + "/recipes/:id/session", handler: "FinishCookingSession", tag: "sessions", summary: "Finish the caller's cooking session", auth: authUser,
+		data: mongo.DeleteResult{}},
+	{method: http.MethodGet, path: prefix + "/dietary", handler: "GetDietaryDictionary", tag: "catalog", summary: "List dietary tags and allergens",
+		data: dietaryDictionary{}},
+	{method: http.MethodGet, path: prefix + "/ingredients", handler: "GetCatalogIngredients", tag: "catalog", summary: "List the ingredient catalog",
+		data: []models.CatalogIngredient{}},
+	{method: http.MethodGet, path: prefix + "/ingredients/unmatched", handler: "GetUnmatchedIngredients", tag: "catalog", summary: "List ingredients missing from the catalog", auth: authAdmin,
+		data: []models.UnmatchedIngredient{}},
+	{method: http.MethodGet, path: prefix + "/ingredients/:id", handler: "GetCatalogIngredientById", tag: "catalog", summary: "Get a catalog ingredient",
+		data: models.CatalogIngredient{}},
+	{method: http.MethodPost, path: prefix + "/ingredients", handler: "PostCatalogIngredient", tag: "catalog", summary: "Add a catalog ingredient", auth: authAdmin,
+		body: models.CatalogIngredient{}, status: http.StatusCreated, data: models.CatalogIngredient{}},
+	{method: http.MethodPut, path: prefix + "/ingredients/:id", handler: "UpdateCatalogIngredientById", tag: "catalog", summary: "Replace a catalog ingredient", auth: authAdmin,
+		body: models.CatalogIngredient{}, data: models.CatalogIngredient{}},
+	{method: http.MethodDelete, path: prefix + "/ingredients/unmatched/:id", handler: "DeleteUnmatchedIngredient", tag: "catalog", summary: "Dismiss an unmatched ingredient", auth: authAdmin,
+		data: mongo.DeleteResult{}},
+	{method: http.MethodDelete, path: prefix + "/ingredients/:id", handler: "DeleteCatalogIngredientById", tag: "catalog", summary: "Delete a catalog ingredient", auth: authAdmin,
+		data: mongo.DeleteResult{}},
+	{method: http.MethodGet, path: prefix + "/jobs", handler: "GetJobs", tag: "jobs", summary: "List the caller's jobs", auth: authUser,
+		data: []models.Job{}},
+	{method: http.MethodGet, path: prefix + "/jobs/:id", handler: "GetJobById", tag: "jobs", summary: "Get a job", auth: authUser,
+		data: models.Job{}},
+	{method: http.MethodGet, path: prefix + "/jobs/:id/result", handler: "GetJobResult", tag: "jobs", summary: "Get a finished job's result", auth: authUser,
+		data: map[string]interface{}{}},
+	{method: http.MethodPost, path: prefix + "/jobs", handler: "PostJob", tag: "jobs", summary: "Queue a job", auth: authUser,
+		body: models.JobRequest{}, status: http.StatusAccepted, data: models.Job{}},
+	{method: http.MethodPost, path: prefix + "/jobs/:id/cancel", handler: "CancelJob", tag: "jobs", summary: "Cancel a job", auth: authUser,
+		data: models.Job{}},
+	{method: http.MethodGet, path: prefix + "/webhooks", handler: "GetWebhooks", tag: "webhooks", summary: "List webhooks", auth: authAdmin,
+		data: []models.WebhookSubscription{}},
+	{method: http.MethodGet, path: prefix + "/webhooks/:id", handler: "GetWebhookById", tag: "webhooks", summary: "Get a webhook", auth: authAdmin,
+		data: models.WebhookSubscription{}},
+	{method: http.MethodPost, path: prefix + "/webhooks", handler: "PostWebhook", tag: "webhooks", summary: "Create a webhook", auth: authAdmin,
+		body: models.WebhookSubscriptionRequest{}, status: http.StatusCreated, data: models.WebhookSubscription{}},
+	{method: http.MethodPut, path: prefix + "/webhooks/:id", handler: "UpdateWebhookById", tag: "webhooks", summary: "Replace a webhook", auth: authAdmin,
+		body: models.WebhookSubscriptionRequest{}, data: models.WebhookSubscription{}},
+	{method: http.MethodDelete, path: prefix + "/webhooks/:id", handler: "DeleteWebhookById", tag: "webhooks", summary: "Delete a webhook", auth: authAdmin,
+		data: mongo.DeleteResult{}},
+	{method: http.MethodGet, path: prefix + "/webhooks/:id/deliveries", handler: "GetWebhookDeliveries", tag: "webhooks", summary: "List a webhook's deliveries", auth: authAdmin,
+		query: []queryParam{{name: "status", description: "Only return deliveries with this status."}},
+		data:  []models.WebhookDelivery{}},
+	{method: http.MethodPost, path: prefix + "/webhooks/:id/deliveries/:deliveryId/redeliver", handler: "RedeliverWebhook", tag: "webhooks", summary: "Send a delivery again", auth: authAdmin,
+		status: http.StatusAccepted, data: models.WebhookDelivery{}},
+	{method: http.MethodGet, path: prefix + "/graphql", handler: "GraphQL", operationId: "queryGraphQL", tag: "graphql", summary: "Run a GraphQL query", auth: authOptional,
+		query: []queryParam{{name: "query", description: "The GraphQL document."}, {name: "operationName", description: "Which operation of the document to run."}},
+		raw:   graphqlResult{}},
+	{method: http.MethodPost, path: prefix + "/graphql", handler: "GraphQL", tag: "graphql", summary: "Run a GraphQL query or mutation", auth: authOptional,
+		body: graphqlParams{}, raw: graphqlResult{}},
+	{method: http.MethodGet, path: prefix + "/openapi.json", handler: "OpenAPISpec", tag: "docs", summary: "Get this document",
+		raw: map[string]interface{}{}},
+	{method: http.MethodGet, path: prefix + "/docs/*filepath", handler: "SwaggerUI", tag: "docs", summary: "Browse this document with Swagger UI",
+		html: true},
+}
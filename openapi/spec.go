@@ -0,0 +1,380 @@
+// Package openapi describes the REST API as an OpenAPI 3 document. The
+// document is generated from the route table in routes.go and the models
+// the handlers bind and return, so it changes along with them.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hopk8412/table-recipes-api/models"
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+)
+
+const bearerAuth = "bearerAuth"
+
+var (
+	specOnce sync.Once
+	spec     *openapi3.T
+	specErr  error
+)
+
+// Spec returns the OpenAPI document of the API. It is built the first time
+// it's asked for.
+func Spec() (*openapi3.T, error) {
+	specOnce.Do(func() {
+		spec, specErr = newSpec()
+	})
+	return spec, specErr
+}
+
+// specBuilder puts the schemas of named models under components, so they're
+// described once and referenced wherever they're used.
+type specBuilder struct {
+	doc *openapi3.T
+	err error
+}
+
+func newSpec() (*openapi3.T, error) {
+	builder := &specBuilder{doc: &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "Table Recipes API",
+			Version:     "1.0.0",
+			Description: "Recipes, their authors and everything around them. Most responses wrap their payload in a RecipeResponse, with the payload under data.data.",
+		},
+		Paths: openapi3.Paths{},
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{},
+			SecuritySchemes: openapi3.SecuritySchemes{
+				bearerAuth: &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme().
+					WithDescription("A Keycloak access token, checked against Keycloak's userinfo endpoint.")},
+			},
+		},
+	}}
+	builder.doc.Components.Schemas["RecipeResponse"] = builder.generate(responses.RecipeResponse{})
+	builder.doc.Components.Schemas["Error"] = &openapi3.SchemaRef{Value: described(
+		envelope(described(openapi3.NewStringSchema(), "What went wrong.")),
+		"The RecipeResponse errors are answered with, status matches the HTTP status.")}
+	builder.doc.Components.Schemas["ProblemDetails"] = &openapi3.SchemaRef{Value: described(
+		builder.generate(responses.ProblemDetails{}).Value,
+		"An RFC 7807 problem, answered to requests that don't match this document.")}
+
+	for _, route := range routes {
+		path, parameters := openapiPath(route.path)
+		pathItem := builder.doc.Paths[path]
+		if pathItem == nil {
+			pathItem = &openapi3.PathItem{}
+			builder.doc.Paths[path] = pathItem
+		}
+		operation := builder.operation(route)
+		operation.Parameters = append(parameters, operation.Parameters...)
+		pathItem.SetOperation(route.method, operation)
+	}
+	if builder.err != nil {
+		return nil, builder.err
+	}
+	return builder.doc, nil
+}
+
+func (builder *specBuilder) operation(route route) *openapi3.Operation {
+	operationId := route.operationId
+	if operationId == "" {
+		operationId = strings.ToLower(route.handler[:1]) + route.handler[1:]
+	}
+	operation := &openapi3.Operation{
+		OperationID: operationId,
+		Summary:     route.summary,
+		Description: route.description,
+		Tags:        []string{route.tag},
+		Responses:   openapi3.Responses{},
+	}
+
+	switch route.auth {
+	case authOptional:
+		operation.Security = &openapi3.SecurityRequirements{{}, {bearerAuth: []string{}}}
+		operation.Description = joinSentences(operation.Description, "Signed in users also see what's shared with them or only visible to them.")
+	case authUser:
+		operation.Security = &openapi3.SecurityRequirements{{bearerAuth: []string{}}}
+	case authSelf:
+		operation.Security = &openapi3.SecurityRequirements{{bearerAuth: []string{}}}
+		operation.Description = joinSentences(operation.Description, "The token must belong to the user in the path.")
+	case authAdmin:
+		operation.Security = &openapi3.SecurityRequirements{{bearerAuth: []string{}}}
+		operation.Description = joinSentences(operation.Description, "Only admins may call it.")
+	default:
+		operation.Security = &openapi3.SecurityRequirements{}
+	}
+
+	for _, param := range route.query {
+		operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{Value: &openapi3.Parameter{
+			Name:        param.name,
+			In:          openapi3.ParameterInQuery,
+			Description: param.description,
+			Schema:      &openapi3.SchemaRef{Value: param.schema()},
+		}})
+	}
+	if route.stream {
+		operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{Value: openapi3.NewHeaderParameter("Last-Event-ID").
+			WithDescription("ID of the last event received, to resume after reconnecting.").
+			WithSchema(openapi3.NewStringSchema())})
+	}
+
+	if route.body != nil {
+		operation.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+			WithRequired(!route.bodyOptional).
+			WithJSONSchemaRef(builder.schemaRef(route.body))}
+	}
+
+	status := route.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := openapi3.NewResponse().WithDescription(http.StatusText(status))
+	switch {
+	case route.stream:
+		response.WithContent(openapi3.Content{"text/event-stream": openapi3.NewMediaType().
+			WithSchema(described(openapi3.NewStringSchema(), "Server-sent events, one per change."))})
+	case route.html:
+		response.WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema(), []string{"text/html"}))
+	case route.raw != nil:
+		response.WithJSONSchemaRef(builder.schemaRef(route.raw))
+	default:
+		data := openapi3.NewObjectSchema().WithPropertyRef("data", builder.schemaRef(route.data))
+		names := make([]string, 0, len(route.extra))
+		for name := range route.extra {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			data.WithPropertyRef(name, builder.schemaRef(route.extra[name]))
+		}
+		response.WithJSONSchema(&openapi3.Schema{AllOf: openapi3.SchemaRefs{
+			openapi3.NewSchemaRef("#/components/schemas/RecipeResponse", builder.doc.Components.Schemas["RecipeResponse"].Value),
+			{Value: openapi3.NewObjectSchema().WithProperty("data", data)},
+		}})
+	}
+	operation.AddResponse(status, response)
+	for _, extraStatus := range route.alsoStatus {
+		operation.AddResponse(extraStatus, openapi3.NewResponse().
+			WithDescription(http.StatusText(extraStatus)).
+			WithContent(response.Content))
+	}
+	errorContent := openapi3.NewContentWithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/Error", builder.doc.Components.Schemas["Error"].Value))
+	operation.AddResponse(http.StatusBadRequest, openapi3.NewResponse().
+		WithDescription("The request doesn't match this document, or the handler rejected it.").
+		WithContent(openapi3.Content{
+			"application/json":         errorContent["application/json"],
+			"application/problem+json": openapi3.NewMediaType().WithSchemaRef(openapi3.NewSchemaRef("#/components/schemas/ProblemDetails", builder.doc.Components.Schemas["ProblemDetails"].Value)),
+		}))
+	operation.Responses["default"] = &openapi3.ResponseRef{Value: openapi3.NewResponse().
+		WithDescription("Error").
+		WithContent(errorContent)}
+	return operation
+}
+
+// schemaRef describes value. Named structs become components and are
+// referenced, slices of them become arrays of references.
+func (builder *specBuilder) schemaRef(value interface{}) *openapi3.SchemaRef {
+	if value == nil {
+		return &openapi3.SchemaRef{Value: &openapi3.Schema{Nullable: true}}
+	}
+	t := reflect.TypeOf(value)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Slice && isComponent(t.Elem()):
+		items := builder.schemaRef(reflect.Zero(t.Elem()).Interface())
+		return &openapi3.SchemaRef{Value: &openapi3.Schema{Type: openapi3.TypeArray, Items: items, Nullable: true}}
+	case isComponent(t):
+		name := t.Name()
+		if _, ok := builder.doc.Components.Schemas[name]; !ok {
+			builder.doc.Components.Schemas[name] = builder.generate(reflect.Zero(t).Interface())
+		}
+		return openapi3.NewSchemaRef("#/components/schemas/"+name, builder.doc.Components.Schemas[name].Value)
+	default:
+		return builder.generate(value)
+	}
+}
+
+// generate reflects on value the way encoding/json would: exported fields
+// without a json tag are included under their Go name.
+func (builder *specBuilder) generate(value interface{}) *openapi3.SchemaRef {
+	schemaRef, err := openapi3gen.NewSchemaRefForValue(value, nil, openapi3gen.UseAllExportedFields(), openapi3gen.SchemaCustomizer(customizeSchema))
+	if err != nil && builder.err == nil {
+		builder.err = err
+	}
+	if schemaRef == nil {
+		return &openapi3.SchemaRef{Value: &openapi3.Schema{}}
+	}
+	return &openapi3.SchemaRef{Value: schemaRef.Value}
+}
+
+var stepType = reflect.TypeOf(models.Step{})
+
+// customizeSchema fills in what reflection can't tell: nil slices, maps,
+// interfaces and pointers are encoded as null, fields gin binds with
+// binding:"required" are required, and steps may also be plain strings.
+func customizeSchema(name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Interface:
+		schema.Nullable = true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+			if jsonName == "" {
+				jsonName = field.Name
+			}
+			// Pointers are dereferenced before their own schema is
+			// customized, the struct holding them marks them nullable
+			if property := schema.Properties[jsonName]; field.Type.Kind() == reflect.Ptr && property != nil && property.Value != nil {
+				property.Value.Nullable = true
+			}
+			if strings.Contains(field.Tag.Get("binding"), "required") {
+				schema.Required = append(schema.Required, jsonName)
+			}
+		}
+	}
+	if t == stepType {
+		object := *schema
+		*schema = openapi3.Schema{
+			OneOf: openapi3.SchemaRefs{
+				{Value: described(openapi3.NewStringSchema(), "A step saved before steps were structured.")},
+				{Value: &object},
+			},
+		}
+	}
+	return nil
+}
+
+// isComponent is whether t gets a schema of its own under components: the
+// API's models and the results of MongoDB writes.
+func isComponent(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.Name() == "" {
+		return false
+	}
+	pkg := t.PkgPath()
+	return strings.HasSuffix(pkg, "/models") || strings.HasSuffix(pkg, "/mongo-driver/mongo")
+}
+
+// envelope is a RecipeResponse whose data.data is described by schema.
+func envelope(schema *openapi3.Schema) *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("status", openapi3.NewIntegerSchema()).
+		WithProperty("message", openapi3.NewStringSchema()).
+		WithProperty("data", openapi3.NewObjectSchema().WithProperty("data", schema))
+}
+
+// openapiPath turns a gin path into an OpenAPI one, with the parameters it
+// declares.
+func openapiPath(ginPath string) (string, openapi3.Parameters) {
+	var parameters openapi3.Parameters
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			segments[i] = "{" + name + "}"
+			parameters = append(parameters, &openapi3.ParameterRef{Value: openapi3.NewPathParameter(name).
+				WithSchema(openapi3.NewStringSchema())})
+		}
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+// OpenAPIPath is openapiPath without the parameters, for looking up the
+// operation of a gin route.
+func OpenAPIPath(ginPath string) string {
+	path, _ := openapiPath(ginPath)
+	return path
+}
+
+func described(schema *openapi3.Schema, description string) *openapi3.Schema {
+	schema.Description = description
+	return schema
+}
+
+func joinSentences(first string, second string) string {
+	if first == "" {
+		return second
+	}
+	return first + " " + second
+}
+
+type authLevel int
+
+const (
+	authNone authLevel = iota
+	// authOptional routes work anonymously and show signed in users more.
+	authOptional
+	authUser
+	// authSelf routes need a token belonging to the user in the path.
+	authSelf
+	authAdmin
+)
+
+// route documents one route registered in main.go.
+type route struct {
+	method  string
+	path    string
+	handler string
+	// operationId is the handler's name by default, set it for handlers
+	// serving several routes.
+	operationId string
+	tag         string
+	summary     string
+	description string
+	auth        authLevel
+	query       []queryParam
+	// body is the request body, bodyOptional when it may be left out.
+	body         interface{}
+	bodyOptional bool
+	// status is the status of a successful response, 200 by default, with
+	// alsoStatus listing any other success statuses.
+	status     int
+	alsoStatus []int
+	// data goes under data.data of the RecipeResponse, extra holds any
+	// other keys of data.
+	data  interface{}
+	extra map[string]interface{}
+	// raw responses aren't wrapped in a RecipeResponse. stream responses
+	// are server-sent events, html ones web pages.
+	raw    interface{}
+	stream bool
+	html   bool
+}
+
+type queryParam struct {
+	name        string
+	kind        string
+	description string
+}
+
+func (param queryParam) schema() *openapi3.Schema {
+	switch param.kind {
+	case "integer":
+		return openapi3.NewIntegerSchema()
+	case "boolean":
+		return openapi3.NewBoolSchema()
+	case "date-time":
+		return openapi3.NewDateTimeSchema()
+	default:
+		return openapi3.NewStringSchema()
+	}
+}
+
+func limitParam(defaultLimit int, maxLimit int) queryParam {
+	description := "How many to return, " + strconv.Itoa(defaultLimit) + " by default"
+	if maxLimit > 0 {
+		description += ", at most " + strconv.Itoa(maxLimit)
+	}
+	return queryParam{name: "limit", kind: "integer", description: description + "."}
+}
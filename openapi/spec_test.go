@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// registeredRoute is a route main.go registers on the router.
+type registeredRoute struct {
+	method  string
+	path    string
+	handler string
+	pos     token.Position
+}
+
+// registeredRoutes reads the routes registered in main.go and routes/ from
+// their source, since building the router would connect to MongoDB.
+func registeredRoutes(t *testing.T) []registeredRoute {
+	files, err := filepath.Glob("../routes/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, "../main.go")
+
+	var found []registeredRoute
+	fset := token.NewFileSet()
+	for _, file := range files {
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		constants := map[string]string{}
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				// Remember prefix := "/api/v1" and the like
+				for i, lhs := range node.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok && i < len(node.Rhs) {
+						if value, ok := stringValue(node.Rhs[i], constants); ok {
+							constants[ident.Name] = value
+						}
+					}
+				}
+			case *ast.CallExpr:
+				selector, ok := node.Fun.(*ast.SelectorExpr)
+				if !ok || len(node.Args) < 2 || !isHTTPMethod(selector.Sel.Name) {
+					return true
+				}
+				path, ok := stringValue(node.Args[0], constants)
+				if !ok {
+					t.Errorf("%s: can't tell the path of the route", fset.Position(node.Pos()))
+					return true
+				}
+				route := registeredRoute{method: selector.Sel.Name, path: path, pos: fset.Position(node.Pos())}
+				if call, ok := node.Args[len(node.Args)-1].(*ast.CallExpr); ok {
+					if handler, ok := call.Fun.(*ast.SelectorExpr); ok {
+						route.handler = handler.Sel.Name
+					}
+				}
+				found = append(found, route)
+			}
+			return true
+		})
+	}
+	return found
+}
+
+func isHTTPMethod(name string) bool {
+	switch name {
+	case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+// stringValue evaluates string literals, variables holding them and their
+// concatenations.
+func stringValue(expr ast.Expr, constants map[string]string) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(expr.Value)
+		return value, err == nil
+	case *ast.Ident:
+		value, ok := constants[expr.Name]
+		return value, ok
+	case *ast.BinaryExpr:
+		left, leftOk := stringValue(expr.X, constants)
+		right, rightOk := stringValue(expr.Y, constants)
+		return left + right, leftOk && rightOk && expr.Op == token.ADD
+	}
+	return "", false
+}
+
+func TestSpecIsValid(t *testing.T) {
+	spec, err := Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = spec.Validate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpecDescribesEveryRoute(t *testing.T) {
+	spec, err := Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered := registeredRoutes(t)
+	if len(registered) == 0 {
+		t.Fatal("found no routes in main.go")
+	}
+	documented := map[string]bool{}
+	for _, route := range registered {
+		if !strings.HasPrefix(route.path, prefix+"/") {
+			continue
+		}
+		path := OpenAPIPath(route.path)
+		documented[route.method+" "+path] = true
+		pathItem := spec.Paths.Find(path)
+		if pathItem == nil || pathItem.GetOperation(route.method) == nil {
+			t.Errorf("%s: %s %s is missing from the OpenAPI document, add it to routes in openapi/routes.go", route.pos, route.method, route.path)
+			continue
+		}
+		if handler := documentedHandler(route.method, route.path); route.handler != "" && handler != route.handler {
+			t.Errorf("%s: %s %s is documented as handled by %s but handled by %s", route.pos, route.method, route.path, handler, route.handler)
+		}
+	}
+	for path, pathItem := range spec.Paths {
+		for method := range pathItem.Operations() {
+			if !documented[method+" "+path] {
+				t.Errorf("%s %s is documented but not registered in main.go", method, path)
+			}
+		}
+	}
+}
+
+func documentedHandler(method string, path string) string {
+	for _, route := range routes {
+		if route.method == method && route.path == path {
+			return route.handler
+		}
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// Validator rejects requests that don't match the OpenAPI document
+// with an RFC 7807 problem before they reach their handler. In gin's test
+// mode it also holds back JSON responses and answers a problem instead if
+// they don't match, so handlers can't drift from the document unnoticed.
+//
+// Authentication is left to the handlers, and routes the document doesn't
+// describe go through unchecked.
+func Validator() gin.HandlerFunc {
+	spec, err := Spec()
+	if err != nil {
+		log.Fatal("Error building OpenAPI document: ", err)
+	}
+	options := &openapi3filter.Options{
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults: true,
+	}
+	options.WithCustomSchemaErrorFunc(schemaErrorMessage)
+
+	return func(c *gin.Context) {
+		// gin already matched the route, find its operation from it rather
+		// than routing again
+		path := OpenAPIPath(c.FullPath())
+		pathItem := spec.Paths[path]
+		if pathItem == nil || pathItem.GetOperation(c.Request.Method) == nil {
+			c.Next()
+			return
+		}
+		operation := pathItem.GetOperation(c.Request.Method)
+
+		pathParams := map[string]string{}
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route: &routers.Route{
+				Spec:      spec,
+				Path:      path,
+				PathItem:  pathItem,
+				Method:    c.Request.Method,
+				Operation: operation,
+			},
+			Options: options,
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			abortWithProblem(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if gin.Mode() != gin.TestMode || isEventStream(operation) {
+			c.Next()
+			return
+		}
+		writer := &heldResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if mediaType, _, _ := mime.ParseMediaType(writer.Header().Get("Content-Type")); mediaType == "application/json" {
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 writer.Status(),
+				Header:                 writer.Header(),
+				Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+			}
+			responseInput.Options.WithCustomSchemaErrorFunc(schemaErrorMessage)
+			responseInput.SetBodyBytes(writer.body.Bytes())
+			if err := openapi3filter.ValidateResponse(c.Request.Context(), responseInput); err != nil {
+				writer.Header().Del("Content-Length")
+				abortWithProblem(c, http.StatusInternalServerError, "The response doesn't match the OpenAPI document: "+err.Error())
+				return
+			}
+		}
+		if writer.written {
+			writer.ResponseWriter.WriteHeaderNow()
+			writer.ResponseWriter.Write(writer.body.Bytes())
+		}
+	}
+}
+
+// heldResponseWriter keeps the response to itself until it's validated.
+type heldResponseWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	written bool
+}
+
+func (w *heldResponseWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *heldResponseWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *heldResponseWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *heldResponseWriter) Written() bool {
+	return w.written
+}
+
+func (w *heldResponseWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *heldResponseWriter) Flush() {}
+
+func isEventStream(operation *openapi3.Operation) bool {
+	for _, response := range operation.Responses {
+		if response.Value != nil && response.Value.Content.Get("text/event-stream") != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaErrorMessage leaves out the schema and value kin-openapi describes
+// schema errors with, they're long and the document has them.
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	message := err.Reason
+	if pointer := err.JSONPointer(); len(pointer) > 0 {
+		message = fmt.Sprintf("%s at /%s", message, strings.Join(pointer, "/"))
+	}
+	// allOf and the like wrap what actually didn't match
+	var origin *openapi3.SchemaError
+	if errors.As(err.Origin, &origin) {
+		message += ": " + schemaErrorMessage(origin)
+	}
+	return message
+}
+
+func abortWithProblem(c *gin.Context, status int, detail string) {
+	problem, err := json.Marshal(responses.ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+	})
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	// Drop the handler's content type, Data keeps one already set
+	c.Writer.Header().Del("Content-Type")
+	c.Data(status, "application/problem+json", problem)
+	c.Abort()
+}
@@ -0,0 +1,94 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hopk8412/table-recipes-api/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validatedRouter serves handler on the search route behind Validator, so
+// the document's schemas apply without the real handler and its database.
+func validatedRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Validator())
+	router.POST(prefix+"/recipes/search", handler)
+	return router
+}
+
+func serve(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, prefix+"/recipes/search", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func decodeProblem(t *testing.T, recorder *httptest.ResponseRecorder) responses.ProblemDetails {
+	t.Helper()
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/problem+json" {
+		t.Fatalf("Content-Type = %q, want application/problem+json", contentType)
+	}
+	var problem responses.ProblemDetails
+	if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("body %q isn't a problem: %v", recorder.Body.String(), err)
+	}
+	return problem
+}
+
+func TestValidatorRejectsInvalidRequests(t *testing.T) {
+	called := false
+	router := validatedRouter(func(c *gin.Context) {
+		called = true
+	})
+
+	recorder := serve(router, `{"searchTerm": 5}`)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+	if called {
+		t.Error("handler was called for an invalid request")
+	}
+	problem := decodeProblem(t, recorder)
+	if problem.Type != "about:blank" || problem.Title != "Bad Request" || problem.Status != http.StatusBadRequest || problem.Instance != prefix+"/recipes/search" {
+		t.Errorf("problem = %+v, want a Bad Request for %s", problem, prefix+"/recipes/search")
+	}
+	if !strings.Contains(problem.Detail, "searchTerm") {
+		t.Errorf("detail %q doesn't point at searchTerm", problem.Detail)
+	}
+}
+
+func TestValidatorReplacesMismatchedResponsesInTestMode(t *testing.T) {
+	router := validatedRouter(func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "message": 200})
+	})
+
+	recorder := serve(router, `{"searchTerm": "soup"}`)
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusInternalServerError)
+	}
+	problem := decodeProblem(t, recorder)
+	if problem.Status != http.StatusInternalServerError || !strings.HasPrefix(problem.Detail, "The response doesn't match the OpenAPI document") {
+		t.Errorf("problem = %+v, want a response mismatch", problem)
+	}
+}
+
+func TestValidatorPassesMatchingResponses(t *testing.T) {
+	router := validatedRouter(func(c *gin.Context) {
+		c.JSON(http.StatusOK, responses.RecipeResponse{Status: http.StatusOK, Message: "success", Data: map[string]interface{}{"data": []interface{}{}, "facets": map[string]interface{}{}}})
+	})
+
+	recorder := serve(router, `{"searchTerm": "soup"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+}
@@ -0,0 +1,5 @@
+// Package recipespb holds the gRPC contract of the recipe service and the
+// code generated from it.
+package recipespb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative recipes.proto
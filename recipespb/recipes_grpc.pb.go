@@ -0,0 +1,375 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: recipes.proto
+
+package recipespb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	RecipeService_Get_FullMethodName         = "/tablerecipes.v1.RecipeService/Get"
+	RecipeService_List_FullMethodName        = "/tablerecipes.v1.RecipeService/List"
+	RecipeService_Search_FullMethodName      = "/tablerecipes.v1.RecipeService/Search"
+	RecipeService_Create_FullMethodName      = "/tablerecipes.v1.RecipeService/Create"
+	RecipeService_Update_FullMethodName      = "/tablerecipes.v1.RecipeService/Update"
+	RecipeService_Delete_FullMethodName      = "/tablerecipes.v1.RecipeService/Delete"
+	RecipeService_ListChanges_FullMethodName = "/tablerecipes.v1.RecipeService/ListChanges"
+)
+
+// RecipeServiceClient is the client API for RecipeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RecipeServiceClient interface {
+	Get(ctx context.Context, in *GetRecipeRequest, opts ...grpc.CallOption) (*Recipe, error)
+	// List returns discoverable recipes, newest first.
+	List(ctx context.Context, in *ListRecipesRequest, opts ...grpc.CallOption) (*ListRecipesResponse, error)
+	// Search filters like POST /recipes/search, newest first.
+	Search(ctx context.Context, in *SearchRecipesRequest, opts ...grpc.CallOption) (*ListRecipesResponse, error)
+	// Create saves a recipe written by the caller.
+	Create(ctx context.Context, in *CreateRecipeRequest, opts ...grpc.CallOption) (*Recipe, error)
+	// Update replaces a recipe's content like PUT /recipes/:id, so fields
+	// left out are cleared.
+	Update(ctx context.Context, in *UpdateRecipeRequest, opts ...grpc.CallOption) (*Recipe, error)
+	Delete(ctx context.Context, in *DeleteRecipeRequest, opts ...grpc.CallOption) (*DeleteRecipeResponse, error)
+	// ListChanges streams recipe changes as they happen, from the time of the
+	// call. Without change streams it only sees the changes made through the
+	// copy of the API it is connected to.
+	ListChanges(ctx context.Context, in *ListChangesRequest, opts ...grpc.CallOption) (RecipeService_ListChangesClient, error)
+}
+
+type recipeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRecipeServiceClient(cc grpc.ClientConnInterface) RecipeServiceClient {
+	return &recipeServiceClient{cc}
+}
+
+func (c *recipeServiceClient) Get(ctx context.Context, in *GetRecipeRequest, opts ...grpc.CallOption) (*Recipe, error) {
+	out := new(Recipe)
+	err := c.cc.Invoke(ctx, RecipeService_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recipeServiceClient) List(ctx context.Context, in *ListRecipesRequest, opts ...grpc.CallOption) (*ListRecipesResponse, error) {
+	out := new(ListRecipesResponse)
+	err := c.cc.Invoke(ctx, RecipeService_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recipeServiceClient) Search(ctx context.Context, in *SearchRecipesRequest, opts ...grpc.CallOption) (*ListRecipesResponse, error) {
+	out := new(ListRecipesResponse)
+	err := c.cc.Invoke(ctx, RecipeService_Search_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recipeServiceClient) Create(ctx context.Context, in *CreateRecipeRequest, opts ...grpc.CallOption) (*Recipe, error) {
+	out := new(Recipe)
+	err := c.cc.Invoke(ctx, RecipeService_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recipeServiceClient) Update(ctx context.Context, in *UpdateRecipeRequest, opts ...grpc.CallOption) (*Recipe, error) {
+	out := new(Recipe)
+	err := c.cc.Invoke(ctx, RecipeService_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recipeServiceClient) Delete(ctx context.Context, in *DeleteRecipeRequest, opts ...grpc.CallOption) (*DeleteRecipeResponse, error) {
+	out := new(DeleteRecipeResponse)
+	err := c.cc.Invoke(ctx, RecipeService_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recipeServiceClient) ListChanges(ctx context.Context, in *ListChangesRequest, opts ...grpc.CallOption) (RecipeService_ListChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RecipeService_ServiceDesc.Streams[0], RecipeService_ListChanges_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &recipeServiceListChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RecipeService_ListChangesClient interface {
+	Recv() (*RecipeChange, error)
+	grpc.ClientStream
+}
+
+type recipeServiceListChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *recipeServiceListChangesClient) Recv() (*RecipeChange, error) {
+	m := new(RecipeChange)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RecipeServiceServer is the server API for RecipeService service.
+// All implementations must embed UnimplementedRecipeServiceServer
+// for forward compatibility
+type RecipeServiceServer interface {
+	Get(context.Context, *GetRecipeRequest) (*Recipe, error)
+	// List returns discoverable recipes, newest first.
+	List(context.Context, *ListRecipesRequest) (*ListRecipesResponse, error)
+	// Search filters like POST /recipes/search, newest first.
+	Search(context.Context, *SearchRecipesRequest) (*ListRecipesResponse, error)
+	// Create saves a recipe written by the caller.
+	Create(context.Context, *CreateRecipeRequest) (*Recipe, error)
+	// Update replaces a recipe's content like PUT /recipes/:id, so fields
+	// left out are cleared.
+	Update(context.Context, *UpdateRecipeRequest) (*Recipe, error)
+	Delete(context.Context, *DeleteRecipeRequest) (*DeleteRecipeResponse, error)
+	// ListChanges streams recipe changes as they happen, from the time of the
+	// call. Without change streams it only sees the changes made through the
+	// copy of the API it is connected to.
+	ListChanges(*ListChangesRequest, RecipeService_ListChangesServer) error
+	mustEmbedUnimplementedRecipeServiceServer()
+}
+
+// UnimplementedRecipeServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRecipeServiceServer struct {
+}
+
+func (UnimplementedRecipeServiceServer) Get(context.Context, *GetRecipeRequest) (*Recipe, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedRecipeServiceServer) List(context.Context, *ListRecipesRequest) (*ListRecipesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedRecipeServiceServer) Search(context.Context, *SearchRecipesRequest) (*ListRecipesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedRecipeServiceServer) Create(context.Context, *CreateRecipeRequest) (*Recipe, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedRecipeServiceServer) Update(context.Context, *UpdateRecipeRequest) (*Recipe, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedRecipeServiceServer) Delete(context.Context, *DeleteRecipeRequest) (*DeleteRecipeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedRecipeServiceServer) ListChanges(*ListChangesRequest, RecipeService_ListChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListChanges not implemented")
+}
+func (UnimplementedRecipeServiceServer) mustEmbedUnimplementedRecipeServiceServer() {}
+
+// UnsafeRecipeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RecipeServiceServer will
+// result in compilation errors.
+type UnsafeRecipeServiceServer interface {
+	mustEmbedUnimplementedRecipeServiceServer()
+}
+
+func RegisterRecipeServiceServer(s grpc.ServiceRegistrar, srv RecipeServiceServer) {
+	s.RegisterService(&RecipeService_ServiceDesc, srv)
+}
+
+func _RecipeService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecipeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecipeServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecipeService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecipeServiceServer).Get(ctx, req.(*GetRecipeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecipeService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecipesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecipeServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecipeService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecipeServiceServer).List(ctx, req.(*ListRecipesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecipeService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRecipesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecipeServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecipeService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecipeServiceServer).Search(ctx, req.(*SearchRecipesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecipeService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRecipeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecipeServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecipeService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecipeServiceServer).Create(ctx, req.(*CreateRecipeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecipeService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRecipeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecipeServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecipeService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecipeServiceServer).Update(ctx, req.(*UpdateRecipeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecipeService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRecipeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecipeServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecipeService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecipeServiceServer).Delete(ctx, req.(*DeleteRecipeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecipeService_ListChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RecipeServiceServer).ListChanges(m, &recipeServiceListChangesServer{stream})
+}
+
+type RecipeService_ListChangesServer interface {
+	Send(*RecipeChange) error
+	grpc.ServerStream
+}
+
+type recipeServiceListChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *recipeServiceListChangesServer) Send(m *RecipeChange) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// RecipeService_ServiceDesc is the grpc.ServiceDesc for RecipeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RecipeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tablerecipes.v1.RecipeService",
+	HandlerType: (*RecipeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _RecipeService_Get_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _RecipeService_List_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _RecipeService_Search_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _RecipeService_Create_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _RecipeService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _RecipeService_Delete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListChanges",
+			Handler:       _RecipeService_ListChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "recipes.proto",
+}
@@ -0,0 +1,11 @@
+package responses
+
+// ProblemDetails is an RFC 7807 problem, answered as application/problem+json
+// to requests the OpenAPI document says are invalid.
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
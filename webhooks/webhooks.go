@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hopk8412/table-recipes-api/models"
+)
+
+// Headers sent with every delivery. Subscribers verify a delivery by
+// computing the HMAC-SHA256 of "<timestamp>.<body>" with their secret and
+// comparing it to the signature header, which is prefixed with "sha256=".
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventId   = "X-Webhook-Event-Id"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// NewSecret makes a random secret to sign a subscription's deliveries with.
+func NewSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts the delivery's event to the subscription and reports how it
+// went. Anything but a 2xx response is an error.
+func Send(ctx context.Context, subscription models.WebhookSubscription, delivery models.WebhookDelivery) (attempt models.WebhookAttempt) {
+	attempt.At = time.Now().UTC()
+	defer func() {
+		attempt.DurationMs = time.Since(attempt.At).Milliseconds()
+	}()
+
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	timestamp := attempt.At.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event.Type)
+	req.Header.Set(HeaderEventId, delivery.Event.Id)
+	req.Header.Set(HeaderDelivery, delivery.Id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("subscriber responded with status %d", resp.StatusCode)
+	}
+	return attempt
+}